@@ -0,0 +1,115 @@
+package v1alpha3
+
+import (
+	"errors"
+	"fmt"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ErrSecretNotResolved is returned when converting a ServiceBinding whose service has not been
+// resolved to a secret yet.
+var ErrSecretNotResolved = errors.New("service has not been resolved to a secret")
+
+// IsDirectSecretReference returns true if the service is a secret, rather than a
+// ProvisionedService.
+func (r *ServiceBindingServiceReference) IsDirectSecretReference() bool {
+	return r.APIVersion == "v1" && r.Kind == "Secret"
+}
+
+// BindingName returns the name of the binding, defaulting to the name of the ServiceBinding.
+func (sb *ServiceBinding) BindingName() string {
+	if sb.Spec.Name != "" {
+		return sb.Spec.Name
+	}
+	return sb.Name
+}
+
+// ToBinding converts the ServiceBinding into the Binding applied to each of its workloads. A
+// service that is directly a secret is bound as is, otherwise the secret resolved from the
+// ProvisionedService is taken from the status. ErrSecretNotResolved is returned until the status
+// names the secret.
+func (sb *ServiceBinding) ToBinding() (binding.Binding, error) {
+	b := binding.Binding{
+		Name:     sb.BindingName(),
+		Type:     sb.Spec.Type,
+		Provider: sb.Spec.Provider,
+	}
+	switch {
+	case sb.Spec.Service.IsDirectSecretReference():
+		b.Secret = corev1.LocalObjectReference{Name: sb.Spec.Service.Name}
+	case sb.Status.Binding != nil && sb.Status.Binding.Name != "":
+		b.Secret = corev1.LocalObjectReference{Name: sb.Status.Binding.Name}
+	default:
+		return binding.Binding{}, fmt.Errorf("%s %q: %w", sb.Spec.Service.Kind, sb.Spec.Service.Name, ErrSecretNotResolved)
+	}
+	if len(sb.Spec.Workload.Containers) != 0 {
+		b.Containers = make([]string, len(sb.Spec.Workload.Containers))
+		copy(b.Containers, sb.Spec.Workload.Containers)
+	}
+	if len(sb.Spec.Env) != 0 {
+		b.Env = make([]binding.EnvMapping, len(sb.Spec.Env))
+		for i, e := range sb.Spec.Env {
+			b.Env[i] = binding.EnvMapping{
+				Name: e.Name,
+				Key:  e.Key,
+			}
+		}
+	}
+	return b, nil
+}
+
+// Validate checks the ServiceBinding is well formed.
+func (sb *ServiceBinding) Validate() field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	if sb.BindingName() == "" {
+		errs = append(errs, field.Required(specPath.Child("name"), "defaults to metadata.name"))
+	}
+
+	workloadPath := specPath.Child("workload")
+	w := &sb.Spec.Workload
+	if w.APIVersion == "" {
+		errs = append(errs, field.Required(workloadPath.Child("apiVersion"), ""))
+	}
+	if w.Kind == "" {
+		errs = append(errs, field.Required(workloadPath.Child("kind"), ""))
+	}
+	switch {
+	case w.Name == "" && w.Selector == nil:
+		errs = append(errs, field.Required(workloadPath, "one of name or selector is required"))
+	case w.Name != "" && w.Selector != nil:
+		errs = append(errs, field.Invalid(workloadPath, w.Name, "name and selector are mutually exclusive"))
+	}
+
+	servicePath := specPath.Child("service")
+	s := &sb.Spec.Service
+	if s.APIVersion == "" {
+		errs = append(errs, field.Required(servicePath.Child("apiVersion"), ""))
+	}
+	if s.Kind == "" {
+		errs = append(errs, field.Required(servicePath.Child("kind"), ""))
+	}
+	if s.Name == "" {
+		errs = append(errs, field.Required(servicePath.Child("name"), ""))
+	}
+
+	names := map[string]bool{}
+	for i, e := range sb.Spec.Env {
+		envPath := specPath.Child("env").Index(i)
+		if e.Name == "" {
+			errs = append(errs, field.Required(envPath.Child("name"), ""))
+		} else if names[e.Name] {
+			errs = append(errs, field.Duplicate(envPath.Child("name"), e.Name))
+		}
+		names[e.Name] = true
+		if e.Key == "" {
+			errs = append(errs, field.Required(envPath.Child("key"), ""))
+		}
+	}
+
+	return errs
+}
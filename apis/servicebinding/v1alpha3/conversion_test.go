@@ -0,0 +1,254 @@
+package v1alpha3
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	binding "github.com/scothis/unstructured-meta-binding"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+func TestServiceBinding_ToBinding(t *testing.T) {
+	tests := []struct {
+		name        string
+		seed        string
+		expected    binding.Binding
+		expectedErr error
+	}{
+		{
+			name: "provisioned service",
+			seed: `
+apiVersion: servicebinding.io/v1alpha3
+kind: ServiceBinding
+metadata:
+  name: shop-db
+spec:
+  name: db
+  type: postgresql
+  provider: bitnami
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: shop
+    containers:
+    - web
+  service:
+    apiVersion: example.com/v1
+    kind: Database
+    name: shop
+  env:
+  - name: DB_HOST
+    key: host
+  - name: DB_TYPE
+    key: type
+status:
+  binding:
+    name: shop-db-credentials
+`,
+			expected: binding.Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "shop-db-credentials",
+				},
+				Containers: []string{"web"},
+				Type:       "postgresql",
+				Provider:   "bitnami",
+				Env: []binding.EnvMapping{
+					{Name: "DB_HOST", Key: "host"},
+					{Name: "DB_TYPE", Key: "type"},
+				},
+			},
+		},
+		{
+			name: "direct secret reference",
+			seed: `
+apiVersion: servicebinding.io/v1alpha3
+kind: ServiceBinding
+metadata:
+  name: shop-db
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+    selector:
+      matchLabels:
+        app.kubernetes.io/part-of: shop
+  service:
+    apiVersion: v1
+    kind: Secret
+    name: shop-db-credentials
+`,
+			expected: binding.Binding{
+				Name: "shop-db",
+				Secret: corev1.LocalObjectReference{
+					Name: "shop-db-credentials",
+				},
+			},
+		},
+		{
+			name: "unresolved service",
+			seed: `
+apiVersion: servicebinding.io/v1alpha3
+kind: ServiceBinding
+metadata:
+  name: shop-db
+spec:
+  workload:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: shop
+  service:
+    apiVersion: example.com/v1
+    kind: Database
+    name: shop
+`,
+			expectedErr: ErrSecretNotResolved,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			sb := &ServiceBinding{}
+			if err := yaml.Unmarshal([]byte(c.seed), sb); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := sb.ToBinding()
+
+			if !errors.Is(err, c.expectedErr) {
+				t.Errorf("ToBinding() expected err %v, got %v", c.expectedErr, err)
+			}
+			if c.expectedErr != nil {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("ToBinding() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestServiceBinding_Validate(t *testing.T) {
+	valid := &ServiceBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "shop-db",
+		},
+		Spec: ServiceBindingSpec{
+			Workload: ServiceBindingWorkloadReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "shop",
+			},
+			Service: ServiceBindingServiceReference{
+				APIVersion: "v1",
+				Kind:       "Secret",
+				Name:       "shop-db-credentials",
+			},
+			Env: []EnvMapping{
+				{Name: "DB_HOST", Key: "host"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		seed     *ServiceBinding
+		expected field.ErrorList
+	}{
+		{
+			name:     "valid",
+			seed:     valid,
+			expected: field.ErrorList{},
+		},
+		{
+			name: "empty",
+			seed: &ServiceBinding{},
+			expected: field.ErrorList{
+				field.Required(field.NewPath("spec", "name"), "defaults to metadata.name"),
+				field.Required(field.NewPath("spec", "workload", "apiVersion"), ""),
+				field.Required(field.NewPath("spec", "workload", "kind"), ""),
+				field.Required(field.NewPath("spec", "workload"), "one of name or selector is required"),
+				field.Required(field.NewPath("spec", "service", "apiVersion"), ""),
+				field.Required(field.NewPath("spec", "service", "kind"), ""),
+				field.Required(field.NewPath("spec", "service", "name"), ""),
+			},
+		},
+		{
+			name: "name and selector",
+			seed: func() *ServiceBinding {
+				sb := valid.DeepCopy()
+				sb.Spec.Workload.Selector = &metav1.LabelSelector{}
+				return sb
+			}(),
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "workload"), "shop", "name and selector are mutually exclusive"),
+			},
+		},
+		{
+			name: "invalid env",
+			seed: func() *ServiceBinding {
+				sb := valid.DeepCopy()
+				sb.Spec.Env = append(sb.Spec.Env, EnvMapping{Name: "DB_HOST"})
+				return sb
+			}(),
+			expected: field.ErrorList{
+				field.Duplicate(field.NewPath("spec", "env").Index(1).Child("name"), "DB_HOST"),
+				field.Required(field.NewPath("spec", "env").Index(1).Child("key"), ""),
+			},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.seed.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Validate() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestServiceBinding_DeepCopy(t *testing.T) {
+	sb := &ServiceBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "shop-db",
+			Labels: map[string]string{"app": "shop"},
+		},
+		Spec: ServiceBindingSpec{
+			Workload: ServiceBindingWorkloadReference{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "shop"},
+				},
+				Containers: []string{"web"},
+			},
+			Env: []EnvMapping{
+				{Name: "DB_HOST", Key: "host"},
+			},
+		},
+		Status: ServiceBindingStatus{
+			Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue},
+			},
+			Binding: &ServiceBindingSecretReference{Name: "shop-db-credentials"},
+		},
+	}
+	copied := sb.DeepCopyObject().(*ServiceBinding)
+	if diff := cmp.Diff(sb, copied); diff != "" {
+		t.Errorf("DeepCopy() (-expected, +actual): %s", diff)
+	}
+
+	copied.Labels["app"] = "other"
+	copied.Spec.Workload.Selector.MatchLabels["app"] = "other"
+	copied.Spec.Workload.Containers[0] = "other"
+	copied.Spec.Env[0].Key = "other"
+	copied.Status.Conditions[0].Status = metav1.ConditionFalse
+	copied.Status.Binding.Name = "other"
+	if sb.Labels["app"] != "shop" || sb.Spec.Workload.Selector.MatchLabels["app"] != "shop" ||
+		sb.Spec.Workload.Containers[0] != "web" || sb.Spec.Env[0].Key != "host" ||
+		sb.Status.Conditions[0].Status != metav1.ConditionTrue || sb.Status.Binding.Name != "shop-db-credentials" {
+		t.Errorf("DeepCopy() shares memory with the original")
+	}
+}
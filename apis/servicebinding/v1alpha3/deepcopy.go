@@ -0,0 +1,141 @@
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func (in *ServiceBinding) DeepCopyInto(out *ServiceBinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+func (in *ServiceBinding) DeepCopy() *ServiceBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *ServiceBinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+func (in *ServiceBindingSpec) DeepCopyInto(out *ServiceBindingSpec) {
+	*out = *in
+	in.Workload.DeepCopyInto(&out.Workload)
+	out.Service = in.Service
+	if in.Env != nil {
+		out.Env = make([]EnvMapping, len(in.Env))
+		copy(out.Env, in.Env)
+	}
+}
+
+func (in *ServiceBindingSpec) DeepCopy() *ServiceBindingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *ServiceBindingWorkloadReference) DeepCopyInto(out *ServiceBindingWorkloadReference) {
+	*out = *in
+	if in.Selector != nil {
+		out.Selector = in.Selector.DeepCopy()
+	}
+	if in.Containers != nil {
+		out.Containers = make([]string, len(in.Containers))
+		copy(out.Containers, in.Containers)
+	}
+}
+
+func (in *ServiceBindingWorkloadReference) DeepCopy() *ServiceBindingWorkloadReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingWorkloadReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *ServiceBindingStatus) DeepCopyInto(out *ServiceBindingStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		out.Conditions = make([]metav1.Condition, len(in.Conditions))
+		for i := range in.Conditions {
+			in.Conditions[i].DeepCopyInto(&out.Conditions[i])
+		}
+	}
+	if in.Binding != nil {
+		out.Binding = new(ServiceBindingSecretReference)
+		*out.Binding = *in.Binding
+	}
+	if in.Applied != nil {
+		out.Applied = new(ServiceBindingApplied)
+		in.Applied.DeepCopyInto(out.Applied)
+	}
+}
+
+func (in *ServiceBindingStatus) DeepCopy() *ServiceBindingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *ServiceBindingApplied) DeepCopyInto(out *ServiceBindingApplied) {
+	*out = *in
+	if in.Workloads != nil {
+		out.Workloads = make([]string, len(in.Workloads))
+		copy(out.Workloads, in.Workloads)
+	}
+}
+
+func (in *ServiceBindingApplied) DeepCopy() *ServiceBindingApplied {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingApplied)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *ServiceBindingList) DeepCopyInto(out *ServiceBindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]ServiceBinding, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+func (in *ServiceBindingList) DeepCopy() *ServiceBindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *ServiceBindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+// Package v1alpha3 contains the servicebinding.io ServiceBinding resource and its conversion to
+// the Binding applied by this module.
+//
+// +groupName=servicebinding.io
+package v1alpha3
@@ -0,0 +1,129 @@
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ServiceBindingConditionReady is true when the service is available and bound to every
+	// workload.
+	ServiceBindingConditionReady = "Ready"
+	// ServiceBindingConditionServiceAvailable is true when the service has been resolved to a
+	// secret.
+	ServiceBindingConditionServiceAvailable = "ServiceAvailable"
+	// ServiceBindingConditionBound is true when every workload has been bound.
+	ServiceBindingConditionBound = "Bound"
+)
+
+// ServiceBindingFinalizer is held by a ServiceBinding until it is removed from its workloads.
+const ServiceBindingFinalizer = "servicebinding.io/finalizer"
+
+// ServiceBinding projects the secret of a service into the workloads that consume it.
+type ServiceBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceBindingSpec   `json:"spec,omitempty"`
+	Status ServiceBindingStatus `json:"status,omitempty"`
+}
+
+type ServiceBindingSpec struct {
+	// Name of the binding, the directory the secret is mounted at within the workload's
+	// containers. Defaults to the name of the ServiceBinding.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Type of the binding, overriding the `type` entry of the secret.
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Provider of the binding, overriding the `provider` entry of the secret.
+	// +optional
+	Provider string `json:"provider,omitempty"`
+	// Workload to bind.
+	Workload ServiceBindingWorkloadReference `json:"workload"`
+	// Service to bind. Either a ProvisionedService, whose `status.binding.name` names the
+	// secret, or the secret itself.
+	Service ServiceBindingServiceReference `json:"service"`
+	// Env projects entries of the secret into the workload as environment variables.
+	// +optional
+	Env []EnvMapping `json:"env,omitempty"`
+}
+
+// ServiceBindingWorkloadReference selects the workload, or workloads, to bind by name or by label
+// selector.
+type ServiceBindingWorkloadReference struct {
+	// APIVersion of the workload.
+	APIVersion string `json:"apiVersion"`
+	// Kind of the workload.
+	Kind string `json:"kind"`
+	// Name of the workload. Mutually exclusive with Selector.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Selector matches the labels of the workloads. Mutually exclusive with Name.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Containers is the names of the containers to bind. If empty, all containers are bound.
+	// +optional
+	Containers []string `json:"containers,omitempty"`
+}
+
+// ServiceBindingServiceReference references the service to bind, within the namespace of the
+// ServiceBinding.
+type ServiceBindingServiceReference struct {
+	// APIVersion of the service.
+	APIVersion string `json:"apiVersion"`
+	// Kind of the service.
+	Kind string `json:"kind"`
+	// Name of the service.
+	Name string `json:"name"`
+}
+
+// EnvMapping projects an entry of the secret as an environment variable.
+type EnvMapping struct {
+	// Name of the environment variable.
+	Name string `json:"name"`
+	// Key of the secret entry holding the value.
+	Key string `json:"key"`
+}
+
+type ServiceBindingStatus struct {
+	// ObservedGeneration is the generation of the ServiceBinding the status reflects.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the state of the binding.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Binding references the secret resolved from the service.
+	// +optional
+	Binding *ServiceBindingSecretReference `json:"binding,omitempty"`
+	// Applied records the binding applied to the workloads, so that it is removed from them once
+	// the binding is renamed or the workloads are no longer referenced.
+	// +optional
+	Applied *ServiceBindingApplied `json:"applied,omitempty"`
+}
+
+// ServiceBindingSecretReference references a secret within the namespace of the ServiceBinding.
+type ServiceBindingSecretReference struct {
+	// Name of the secret.
+	Name string `json:"name"`
+}
+
+// ServiceBindingApplied is a binding applied to workloads of a kind.
+type ServiceBindingApplied struct {
+	// Name of the binding within the workloads.
+	Name string `json:"name"`
+	// APIVersion of the workloads.
+	APIVersion string `json:"apiVersion"`
+	// Kind of the workloads.
+	Kind string `json:"kind"`
+	// Workloads is the names of the workloads the binding is applied to, in sorted order.
+	// +optional
+	Workloads []string `json:"workloads,omitempty"`
+}
+
+// ServiceBindingList is a list of ServiceBindings.
+type ServiceBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ServiceBinding `json:"items"`
+}
@@ -1,8 +1,17 @@
 package binding
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type Binding struct {
@@ -10,34 +19,629 @@ type Binding struct {
 	Name string
 	// Secret holding the credentails to bind
 	Secret corev1.LocalObjectReference
-	// Containers is a set of names of container to bind. If empty, all containers are bound.
+	// Containers is a set of names of container to bind. If empty, all containers selected by
+	// Selection are bound. Named containers are bound even if excluded by the
+	// DefaultContainerSelection, though not if excluded by an explicit Selection.
 	Containers []string
+	// Selection determines the init and application containers to bind. Defaults to
+	// DefaultContainerSelection, which skips well known sidecar containers.
+	// +optional
+	Selection *ContainerSelection
+	// SecretData is the resolved content of the secret. When set, a checksum of the data is
+	// written to the pod template annotations so that rotating the secret rolls the workload.
+	// +optional
+	SecretData map[string][]byte
+	// SecretChecksum is a caller supplied digest of the secret's content, used in place of a
+	// checksum of SecretData.
+	// +optional
+	SecretChecksum string
+	// Type of the binding, overriding the `type` entry of the secret both within the mounted
+	// binding and when projected as an environment variable.
+	// +optional
+	Type string
+	// Provider of the binding, overriding the `provider` entry of the secret both within the
+	// mounted binding and when projected as an environment variable.
+	// +optional
+	Provider string
+	// Env projects entries of the secret into each bound container as environment variables.
+	// +optional
+	Env []EnvMapping
+}
+
+// EnvMapping projects an entry of the binding's secret as an environment variable.
+type EnvMapping struct {
+	// Name of the environment variable.
+	Name string
+	// Key of the secret entry holding the value.
+	Key string
+}
+
+type BindOptions struct {
+	// ServiceBindingRoot is the directory bindings are mounted within for containers that do not
+	// already define the `SERVICE_BINDING_ROOT` environment variable. Defaults to `/bindings`.
+	// +optional
+	ServiceBindingRoot string
+	// VolumeName returns the name of the volume projecting the binding's secret. Defaults to
+	// DefaultVolumeName.
+	// +optional
+	VolumeName func(b *Binding) string
+	// ReadOnly mounts the binding volume read-only. Defaults to true.
+	// +optional
+	ReadOnly *bool
+	// DefaultMode is the mode bits of the files projected from the secret. If not specified,
+	// the Kubernetes default is used.
+	// +optional
+	DefaultMode *int32
+	// SubPath, when set, mounts the path within the secret volume rather than the volume's root.
+	// +optional
+	SubPath string
+	// LabelPrefix, when set, labels the pod template with `<LabelPrefix><binding name>: "true"`
+	// so that bound pods can be selected. The prefix should end with `/`, for example
+	// `bindings.example.com/`. Existing labels, including those matched by a workload's
+	// selector, are never modified. Workloads whose mapping has no Labels are not labeled.
+	// +optional
+	LabelPrefix string
+	// ConflictPolicy determines how existing volumes and volume mounts that collide with the
+	// binding are treated. Defaults to ConflictPolicyFail.
+	// +optional
+	ConflictPolicy ConflictPolicy
+	// SecretGetter, when set, is used to check that the binding's secret exists and holds the
+	// `type` entry and the entries projected as environment variables. A SecretError is returned
+	// before the workload is changed if it does not.
+	// +optional
+	SecretGetter SecretGetter
+}
+
+func (o *BindOptions) Default() {
+	if o.ServiceBindingRoot == "" {
+		o.ServiceBindingRoot = "/bindings"
+	}
+	if o.VolumeName == nil {
+		o.VolumeName = DefaultVolumeName
+	}
+	if o.ReadOnly == nil {
+		readOnly := true
+		o.ReadOnly = &readOnly
+	}
+	if o.ConflictPolicy == "" {
+		o.ConflictPolicy = ConflictPolicyFail
+	}
+}
+
+// DefaultVolumeName names the volume after the binding. Names that would exceed the 63
+// character limit of a DNS label are truncated and suffixed with a hash of the binding name to
+// remain unique. Names that are not otherwise valid DNS labels are replaced by the hash.
+func DefaultVolumeName(b *Binding) string {
+	name := fmt.Sprintf("binding-%s", b.Name)
+	if len(validation.IsDNS1123Label(name)) == 0 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(b.Name))
+	hash := hex.EncodeToString(sum[:])[:8]
+	if len(name) > validation.DNS1123LabelMaxLength {
+		prefix := strings.TrimRight(name[:validation.DNS1123LabelMaxLength-len(hash)-1], "-")
+		if len(validation.IsDNS1123Label(prefix)) == 0 {
+			return fmt.Sprintf("%s-%s", prefix, hash)
+		}
+	}
+	return fmt.Sprintf("binding-%s", hash)
 }
 
-func (b *Binding) Bind(obj runtime.Object, m *PodMapping) error {
+// Bind applies the binding to the object, returning a description of what changed. The object
+// is not modified when an error is returned.
+func (b *Binding) Bind(obj runtime.Object, m *PodMapping, opts BindOptions) (BindResult, error) {
+	if err := b.validateName(); err != nil {
+		return BindResult{}, err
+	}
+	opts.Default()
 	mpt, err := m.ToMeta(obj)
+	if err != nil {
+		return BindResult{}, err
+	}
+	before := mpt.DeepCopy()
+	skips, err := b.apply(&mpt, opts)
+	if err != nil {
+		return BindResult{}, err
+	}
+	if err := m.FromMeta(obj, mpt); err != nil {
+		return BindResult{}, err
+	}
+	return newBindResult(before, &mpt, skips), nil
+}
+
+// BindAll applies each binding to the object with a single conversion. Bindings are applied in
+// order of their name so the resulting volumes and volume mounts are deterministic regardless of
+// the order provided. Conflicts between bindings are aggregated into the returned error, in which
+// case the object is not modified. A container is reported as skipped only when every binding
+// skipped it.
+func BindAll(obj runtime.Object, m *PodMapping, bindings []Binding, opts BindOptions) (BindResult, error) {
+	opts.Default()
+	mpt, err := m.ToMeta(obj)
+	if err != nil {
+		return BindResult{}, err
+	}
+	before := mpt.DeepCopy()
+	skips, err := bindAll(&mpt, bindings, opts)
+	if err != nil {
+		return BindResult{}, err
+	}
+	if err := m.FromMeta(obj, mpt); err != nil {
+		return BindResult{}, err
+	}
+	return newBindResult(before, &mpt, skips), nil
+}
+
+// bindAll applies the bindings to the MetaPodTemplate in order of their name, returning the
+// reasons each container was skipped by every binding. Options must already be defaulted.
+func bindAll(mpt *MetaPodTemplate, bindings []Binding, opts BindOptions) ([]string, error) {
+	records, err := readRecords(mpt.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]Binding, len(bindings))
+	copy(sorted, bindings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	errs := []error{}
+	skips := [][]string{}
+	names := map[string]bool{}
+	volumes := map[string]string{}
+	for name, record := range records {
+		if record.Volume != "" {
+			volumes[record.Volume] = name
+		}
+	}
+	for i := range sorted {
+		b := &sorted[i]
+		if err := b.validateName(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if names[b.Name] {
+			errs = append(errs, fmt.Errorf("duplicate binding name %q", b.Name))
+			continue
+		}
+		names[b.Name] = true
+		if b.Secret.Name != "" {
+			volumeName := opts.VolumeName(b)
+			if other, ok := volumes[volumeName]; ok && other != b.Name {
+				errs = append(errs, fmt.Errorf("bindings %q and %q both use volume %q", other, b.Name, volumeName))
+				continue
+			}
+			volumes[volumeName] = b.Name
+		}
+		s, err := b.apply(mpt, opts)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("binding %q: %w", b.Name, err))
+			continue
+		}
+		skips = append(skips, s)
+	}
+	if len(errs) != 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return mergeSkips(skips, len(mpt.Containers)), nil
+}
+
+// checksum returns the digest of the binding's secret, or an empty string if the content of the
+// secret is unknown.
+func (b *Binding) checksum() string {
+	if b.SecretChecksum != "" {
+		return b.SecretChecksum
+	}
+	if b.SecretData == nil {
+		return ""
+	}
+	keys := make([]string, 0, len(b.SecretData))
+	for k := range b.SecretData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		// length prefix each entry so that moving bytes between a key and value changes the sum
+		fmt.Fprintf(h, "%d:%s%d:", len(k), k, len(b.SecretData[k]))
+		h.Write(b.SecretData[k])
+	}
+	return fmt.Sprintf("sha256:%s", hex.EncodeToString(h.Sum(nil)))
+}
+
+// Unbind removes the binding from the object, returning a description of what changed. Only the
+// environment variables, volume mounts and volume recorded as owned by the binding are removed,
+// entries defined by the user are left untouched. An object that was not bound by the binding is
+// not modified.
+func (b *Binding) Unbind(obj runtime.Object, m *PodMapping) (BindResult, error) {
+	_, result, err := b.unbindResult(obj, m)
+	return result, err
+}
+
+// validateName rejects binding names that are not a single path segment. The name is the
+// directory the secret is mounted at within the service binding root, an empty name or a name
+// that walks the path would mount the secret over the root or outside of it.
+func (b *Binding) validateName() error {
+	if b.Name == "" || b.Name == "." || b.Name == ".." || strings.Contains(b.Name, "/") {
+		return fmt.Errorf("invalid binding name %q, must be a single path segment", b.Name)
+	}
+	return nil
+}
+
+// apply binds the MetaPodTemplate, returning the reasons each container was skipped indexed by
+// container. Containers bound by the binding have an empty reason. Options must already be
+// defaulted.
+func (b *Binding) apply(mpt *MetaPodTemplate, opts BindOptions) ([]string, error) {
+	if err := b.checkSecret(opts.SecretGetter); err != nil {
+		return nil, err
+	}
+	records, err := readRecords(mpt.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	previous := records[b.Name]
+	delete(records, b.Name)
+	record := bindingRecord{}
+
+	volumeName := opts.VolumeName(b)
+	bindVolume := b.Secret.Name != ""
+	var skippedVolume *ConflictError
+	if bindVolume {
+		volume := b.volume(volumeName, opts)
+		if i := indexVolume(mpt.Volumes, volumeName); i < 0 {
+			mpt.Volumes = append(mpt.Volumes, volume)
+		} else if previous.Volume == volumeName {
+			mpt.Volumes[i] = volume
+		} else {
+			conflict := &ConflictError{
+				Binding:  b.Name,
+				Existing: volumeName,
+				Owner:    volumeOwner(records, volumeName),
+				Reason:   "volume already exists",
+			}
+			switch {
+			case opts.ConflictPolicy == ConflictPolicySkip:
+				bindVolume = false
+				skippedVolume = conflict
+			case opts.ConflictPolicy == ConflictPolicyTakeOver && conflict.Owner == "":
+				mpt.Volumes[i] = volume
+			default:
+				return nil, conflict
+			}
+		}
+	}
+	if bindVolume {
+		record.Volume = volumeName
+	}
+	if previous.Volume != "" && previous.Volume != record.Volume {
+		removeVolume(mpt, previous.Volume)
+	}
+	if opts.LabelPrefix != "" {
+		key := qualifiedKey(opts.LabelPrefix, b.Name)
+		if _, ok := mpt.Labels[key]; !ok || previous.Label == key {
+			if mpt.Labels == nil {
+				mpt.Labels = map[string]string{}
+			}
+			mpt.Labels[key] = "true"
+			record.Label = key
+		}
+	}
+	if previous.Label != "" && previous.Label != record.Label {
+		delete(mpt.Labels, previous.Label)
+	}
+	if checksum := b.checksum(); checksum != "" {
+		mpt.Annotations[checksumAnnotation(b.Name)] = checksum
+	} else {
+		delete(mpt.Annotations, checksumAnnotation(b.Name))
+	}
+	// the overrides are projected into the volume from the annotations
+	for key, value := range map[string]string{typeAnnotation(b.Name): b.Type, providerAnnotation(b.Name): b.Provider} {
+		if bindVolume && value != "" {
+			mpt.Annotations[key] = value
+		} else {
+			delete(mpt.Annotations, key)
+		}
+	}
+
+	skips := make([]string, len(mpt.Containers))
+	for i := range mpt.Containers {
+		c := &mpt.Containers[i]
+		selected, err := b.selects(c)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !selected:
+			skips[i] = fmt.Sprintf("binding %q: container not selected", b.Name)
+		case skippedVolume != nil:
+			// the environment is still injected, but without the volume there is nothing to mount
+			if _, err := b.bindContainer(c, &record, previous, records, false, volumeName, opts); err != nil {
+				return nil, err
+			}
+			skips[i] = skippedVolume.Error()
+		default:
+			conflict, err := b.bindContainer(c, &record, previous, records, bindVolume, volumeName, opts)
+			if err != nil {
+				return nil, err
+			}
+			if conflict != nil {
+				skips[i] = conflict.Error()
+			}
+		}
+
+		// release entries the binding no longer manages
+		for _, name := range previous.Env[c.Name] {
+			if !record.ownsEnv(c.Name, name) && !ownedEnv(records, c.Name, name) {
+				removeEnv(c, name)
+			}
+		}
+		for _, mountPath := range previous.VolumeMounts[c.Name] {
+			if !record.ownsVolumeMount(c.Name, mountPath) {
+				removeVolumeMount(c, mountPath)
+			}
+		}
+	}
+
+	records[b.Name] = record
+	if err := writeRecords(mpt.Annotations, records); err != nil {
+		return nil, err
+	}
+	return skips, nil
+}
+
+// bindContainer injects the binding into the container, recording the entries owned by the
+// binding. The conflict is returned when the volume mount is skipped.
+func (b *Binding) bindContainer(c *MetaContainer, record *bindingRecord, previous bindingRecord, records map[string]bindingRecord, bindVolume bool, volumeName string, opts BindOptions) (*ConflictError, error) {
+	injected := []corev1.EnvVar{}
+	serviceBindingRoot := ""
+	if j := indexEnv(c.Env, "SERVICE_BINDING_ROOT"); j >= 0 {
+		serviceBindingRoot = c.Env[j].Value
+		if previous.ownsEnv(c.Name, "SERVICE_BINDING_ROOT") || ownedEnv(records, c.Name, "SERVICE_BINDING_ROOT") {
+			// shared with the other bindings of the container
+			record.addEnv(c.Name, "SERVICE_BINDING_ROOT")
+		}
+	} else {
+		serviceBindingRoot = opts.ServiceBindingRoot
+		injected = append(injected, corev1.EnvVar{
+			Name:  "SERVICE_BINDING_ROOT",
+			Value: serviceBindingRoot,
+		})
+		record.addEnv(c.Name, "SERVICE_BINDING_ROOT")
+	}
+	projected, err := b.projectedEnv()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range projected {
+		// entries owned by the binding are updated in place, preserving their position
+		if j := indexEnv(c.Env, e.Name); j >= 0 && previous.ownsEnv(c.Name, e.Name) {
+			c.Env[j] = e
+		} else {
+			injected = append(injected, e)
+		}
+		record.addEnv(c.Name, e.Name)
+	}
+	env, err := insertEnv(c.Env, injected...)
+	if err != nil {
+		return nil, fmt.Errorf("container %q: %w", c.Name, err)
+	}
+	c.Env = env
+
+	if bindVolume {
+		mount := corev1.VolumeMount{
+			Name:      volumeName,
+			ReadOnly:  *opts.ReadOnly,
+			MountPath: path.Join(serviceBindingRoot, b.Name),
+			SubPath:   opts.SubPath,
+		}
+		mounts, conflict, err := b.mount(c, mount, previous, records, opts)
+		if err != nil {
+			return nil, err
+		}
+		if conflict != nil {
+			return conflict, nil
+		}
+		c.VolumeMounts = mounts
+		record.addVolumeMount(c.Name, mount.MountPath)
+	}
+	return nil, nil
+}
+
+// mount returns the container's volume mounts including the mount, or the conflict if the
+// container is skipped. A mount previously owned by the binding is updated in place.
+func (b *Binding) mount(c *MetaContainer, mount corev1.VolumeMount, previous bindingRecord, records map[string]bindingRecord, opts BindOptions) ([]corev1.VolumeMount, *ConflictError, error) {
+	owned := indexOwnedVolumeMount(c.VolumeMounts, previous.VolumeMounts[c.Name])
+	mounts := []corev1.VolumeMount{}
+	var conflict *ConflictError
+	takeOver := opts.ConflictPolicy == ConflictPolicyTakeOver
+	replaced := owned >= 0
+	for j := range c.VolumeMounts {
+		if j == owned {
+			mounts = append(mounts, mount)
+			continue
+		}
+		existing := c.VolumeMounts[j]
+		reason, nested := mountConflict(existing, mount)
+		if reason == "" {
+			mounts = append(mounts, existing)
+			continue
+		}
+		owner := volumeMountOwner(records, c.Name, existing.MountPath)
+		if conflict == nil {
+			conflict = &ConflictError{
+				Binding:   b.Name,
+				Container: c.Name,
+				Existing:  existing.MountPath,
+				Owner:     owner,
+				Reason:    reason,
+			}
+		}
+		if nested || owner != "" {
+			takeOver = false
+		}
+		if !replaced {
+			// take over the position of the first colliding mount
+			mounts = append(mounts, mount)
+			replaced = true
+		}
+	}
+	if conflict != nil {
+		switch {
+		case opts.ConflictPolicy == ConflictPolicySkip:
+			return nil, conflict, nil
+		case takeOver:
+			return mounts, nil, nil
+		default:
+			return nil, nil, conflict
+		}
+	}
+	if !replaced {
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil, nil
+}
+
+// volume returns the volume mounting the binding's secret. When the binding overrides its Type or
+// Provider, the secret is projected together with the pod template annotations holding the
+// overrides, which take the place of the secret's `type` and `provider` entries.
+func (b *Binding) volume(name string, opts BindOptions) corev1.Volume {
+	overrides := []corev1.DownwardAPIVolumeFile{}
+	if b.Type != "" {
+		overrides = append(overrides, annotationFile("type", typeAnnotation(b.Name)))
+	}
+	if b.Provider != "" {
+		overrides = append(overrides, annotationFile("provider", providerAnnotation(b.Name)))
+	}
+	if len(overrides) == 0 {
+		return corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  b.Secret.Name,
+					DefaultMode: opts.DefaultMode,
+				},
+			},
+		}
+	}
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				// entries of later sources replace those of earlier sources with the same path
+				Sources: []corev1.VolumeProjection{
+					{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: b.Secret,
+						},
+					},
+					{
+						DownwardAPI: &corev1.DownwardAPIProjection{
+							Items: overrides,
+						},
+					},
+				},
+				DefaultMode: opts.DefaultMode,
+			},
+		},
+	}
+}
+
+// annotationFile projects the value of the pod annotation to the path.
+func annotationFile(path, annotation string) corev1.DownwardAPIVolumeFile {
+	return corev1.DownwardAPIVolumeFile{
+		Path: path,
+		FieldRef: &corev1.ObjectFieldSelector{
+			FieldPath: fmt.Sprintf("metadata.annotations['%s']", annotation),
+		},
+	}
+}
+
+// unbind removes the entries recorded as owned by the named binding from the MetaPodTemplate.
+// Environment variables shared with other bindings are retained.
+func unbind(mpt *MetaPodTemplate, name string) error {
+	records, err := readRecords(mpt.Annotations)
 	if err != nil {
 		return err
 	}
-	// TODO inject volume
+	record, ok := records[name]
+	if !ok {
+		return nil
+	}
+	delete(records, name)
+	delete(mpt.Annotations, checksumAnnotation(name))
+	delete(mpt.Annotations, typeAnnotation(name))
+	delete(mpt.Annotations, providerAnnotation(name))
+	if record.Label != "" {
+		delete(mpt.Labels, record.Label)
+	}
+	if record.Volume != "" {
+		removeVolume(mpt, record.Volume)
+	}
 	for i := range mpt.Containers {
 		c := &mpt.Containers[i]
-		// TODO skip container if not allowed
-		serviceBindingRoot := ""
-		for _, e := range c.Env {
-			if e.Name == "SERVICE_BINDING_ROOT" {
-				serviceBindingRoot = e.Value
-				break
+		for _, envName := range record.Env[c.Name] {
+			if !ownedEnv(records, c.Name, envName) {
+				removeEnv(c, envName)
 			}
 		}
-		if serviceBindingRoot == "" {
-			serviceBindingRoot = "/bindings"
-			c.Env = append(c.Env, corev1.EnvVar{
-				Name:  "SERVICE_BINDING_ROOT",
-				Value: serviceBindingRoot,
-			})
+		for _, mountPath := range record.VolumeMounts[c.Name] {
+			removeVolumeMount(c, mountPath)
+		}
+	}
+	return writeRecords(mpt.Annotations, records)
+}
+
+// removeVolume removes the named volume from the MetaPodTemplate.
+func removeVolume(mpt *MetaPodTemplate, name string) {
+	if i := indexVolume(mpt.Volumes, name); i >= 0 {
+		mpt.Volumes = append(mpt.Volumes[:i], mpt.Volumes[i+1:]...)
+	}
+}
+
+// removeEnv removes the named environment variable from the container.
+func removeEnv(c *MetaContainer, name string) {
+	if i := indexEnv(c.Env, name); i >= 0 {
+		c.Env = append(c.Env[:i], c.Env[i+1:]...)
+	}
+}
+
+// removeVolumeMount removes the volume mount at the path from the container.
+func removeVolumeMount(c *MetaContainer, mountPath string) {
+	if i := indexOwnedVolumeMount(c.VolumeMounts, []string{mountPath}); i >= 0 {
+		c.VolumeMounts = append(c.VolumeMounts[:i], c.VolumeMounts[i+1:]...)
+	}
+}
+
+// indexVolume returns the index of the volume with the name, or -1 if not found.
+func indexVolume(volumes []corev1.Volume, name string) int {
+	for i := range volumes {
+		if volumes[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexVolumeMount returns the index of the first mount of the named volume, or -1 if not
+// found.
+func indexVolumeMount(mounts []corev1.VolumeMount, volumeName string) int {
+	for i := range mounts {
+		if mounts[i].Name == volumeName {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexOwnedVolumeMount returns the index of the first volume mount at one of the paths, or -1
+// if not found.
+func indexOwnedVolumeMount(mounts []corev1.VolumeMount, paths []string) int {
+	for i := range mounts {
+		for _, p := range paths {
+			if mounts[i].MountPath == p {
+				return i
+			}
 		}
-		// TODO do other stuff with the container
 	}
-	return m.FromMeta(obj, mpt)
+	return -1
 }
@@ -13,9 +13,13 @@ import (
 )
 
 func TestBinding(t *testing.T) {
+	readWrite := false
+	defaultMode := int32(0400)
+
 	tests := []struct {
 		name        string
 		binding     Binding
+		options     BindOptions
 		mapping     PodMapping
 		seed        runtime.Object
 		expected    runtime.Object
@@ -23,7 +27,7 @@ func TestBinding(t *testing.T) {
 	}{
 		{
 			name:    "podspecable",
-			binding: Binding{},
+			binding: Binding{Name: "db"},
 			mapping: PodMapping{},
 			seed: &appsv1.Deployment{
 				Spec: appsv1.DeploymentSpec{
@@ -59,7 +63,9 @@ func TestBinding(t *testing.T) {
 				Spec: appsv1.DeploymentSpec{
 					Template: corev1.PodTemplateSpec{
 						ObjectMeta: metav1.ObjectMeta{
-							Annotations: map[string]string{},
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"env":{"hello-2":["SERVICE_BINDING_ROOT"],"init-hello":["SERVICE_BINDING_ROOT"],"init-hello-2":["SERVICE_BINDING_ROOT"]}}}`,
+							},
 						},
 						Spec: corev1.PodSpec{
 							InitContainers: []corev1.Container{
@@ -114,9 +120,10 @@ func TestBinding(t *testing.T) {
 		},
 		{
 			name:    "almost podspecable",
-			binding: Binding{},
+			binding: Binding{Name: "db"},
 			mapping: PodMapping{
 				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
 				Containers: []ContainerMapping{
 					{
 						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
@@ -169,7 +176,9 @@ func TestBinding(t *testing.T) {
 						Spec: batchv1.JobSpec{
 							Template: corev1.PodTemplateSpec{
 								ObjectMeta: metav1.ObjectMeta{
-									Annotations: map[string]string{},
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"db":{"env":{"hello-2":["SERVICE_BINDING_ROOT"],"init-hello":["SERVICE_BINDING_ROOT"],"init-hello-2":["SERVICE_BINDING_ROOT"]}}}`,
+									},
 								},
 								Spec: corev1.PodSpec{
 									InitContainers: []corev1.Container{
@@ -226,14 +235,16 @@ func TestBinding(t *testing.T) {
 		},
 		{
 			name:    "no containers",
-			binding: Binding{},
+			binding: Binding{Name: "db"},
 			mapping: PodMapping{},
 			seed:    &appsv1.Deployment{},
 			expected: &appsv1.Deployment{
 				Spec: appsv1.DeploymentSpec{
 					Template: corev1.PodTemplateSpec{
 						ObjectMeta: metav1.ObjectMeta{
-							Annotations: map[string]string{},
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{}}`,
+							},
 						},
 						Spec: corev1.PodSpec{
 							Volumes: []corev1.Volume{},
@@ -243,55 +254,5286 @@ func TestBinding(t *testing.T) {
 			},
 		},
 		{
-			name:    "invalid container jsonpath",
-			binding: Binding{},
-			mapping: PodMapping{
-				Containers: []ContainerMapping{
-					{
-						Path: "[",
+			name:    "env referencing service binding root",
+			binding: Binding{Name: "db"},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "PORT",
+											Value: "8080",
+										},
+										{
+											Name:  "CONFIG",
+											Value: "$(SERVICE_BINDING_ROOT)/db",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"env":{"hello":["SERVICE_BINDING_ROOT"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "PORT",
+											Value: "8080",
+										},
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+										{
+											Name:  "CONFIG",
+											Value: "$(SERVICE_BINDING_ROOT)/db",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{},
+						},
 					},
 				},
 			},
-			seed:        &appsv1.Deployment{},
-			expectedErr: true,
 		},
 		{
-			name:        "conversion error",
-			binding:     Binding{},
-			mapping:     PodMapping{},
-			seed:        &BadMarshalJSON{},
-			expectedErr: true,
+			name: "bind secret",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "my-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 		},
-	}
-
-	for _, c := range tests {
-		t.Run(c.name, func(t *testing.T) {
-			actual := c.seed.DeepCopyObject()
-			m := &c.mapping
-			m.Default()
-			err := c.binding.Bind(actual, m)
-
-			if (err != nil) != c.expectedErr {
-				t.Errorf("Bind() expected err: %v", err)
-			}
-			if c.expectedErr {
-				return
-			}
-			if diff := cmp.Diff(c.expected, actual); diff != "" {
-				t.Errorf("Bind() (-expected, +actual): %s", diff)
-			}
-		})
-	}
-}
-
-var (
-	_ runtime.Object = (*BadMarshalJSON)(nil)
-)
-
-type BadMarshalJSON struct {
-	metav1.TypeMeta
-	metav1.ObjectMeta
-}
-
-func (r *BadMarshalJSON) MarshalJSON() ([]byte, error)   { return nil, fmt.Errorf("bad json marshal") }
-func (r *BadMarshalJSON) DeepCopyObject() runtime.Object { return r }
+		{
+			name: "custom service binding root",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				ServiceBindingRoot: "/custom/path",
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/custom/path/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/custom/path",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/custom/path/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "my-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "custom volume name",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				VolumeName: func(b *Binding) string {
+					return "custom-" + b.Name
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"custom-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "custom-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "custom-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "my-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "long binding name",
+			binding: Binding{
+				Name: "a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit":{"volume":"binding-a-binding-name-that-is-long-enough-to-exceed-t-e076c5d0","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-a-binding-name-that-is-long-enough-to-exceed-t-e076c5d0",
+											MountPath: "/bindings/a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-a-binding-name-that-is-long-enough-to-exceed-t-e076c5d0",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "my-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid volume name",
+			binding: Binding{
+				Name: "DB_Main",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"DB_Main":{"volume":"binding-18ccb3e6","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/DB_Main"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-18ccb3e6",
+											MountPath: "/bindings/DB_Main",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-18ccb3e6",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "my-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "empty binding name",
+			binding: Binding{
+				Name: "",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "binding name with a slash",
+			binding: Binding{
+				Name: "a/b",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "binding name escaping the binding root",
+			binding: Binding{
+				Name: "../../etc",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "current directory binding name",
+			binding: Binding{
+				Name: ".",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "parent directory binding name",
+			binding: Binding{
+				Name: "..",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "read write",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				ReadOnly: &readWrite,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "my-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "default mode",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				DefaultMode: &defaultMode,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName:  "my-secret",
+											DefaultMode: &defaultMode,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "sub path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				SubPath: "data",
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+											SubPath:   "data",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "my-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "bind secret almost podspecable",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/bindings",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "binding-db",
+													MountPath: "/bindings/db",
+													ReadOnly:  true,
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "binding-db",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "my-secret",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "custom service binding root almost podspecable",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				ServiceBindingRoot: "/custom/path",
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/custom/path/db"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/custom/path",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "binding-db",
+													MountPath: "/custom/path/db",
+													ReadOnly:  true,
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "binding-db",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "my-secret",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "custom volume name almost podspecable",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				VolumeName: func(b *Binding) string {
+					return "custom-" + b.Name
+				},
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"db":{"volume":"custom-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/bindings",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "custom-db",
+													MountPath: "/bindings/db",
+													ReadOnly:  true,
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "custom-db",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "my-secret",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "long binding name almost podspecable",
+			binding: Binding{
+				Name: "a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit":{"volume":"binding-a-binding-name-that-is-long-enough-to-exceed-t-e076c5d0","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/bindings",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "binding-a-binding-name-that-is-long-enough-to-exceed-t-e076c5d0",
+													MountPath: "/bindings/a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit",
+													ReadOnly:  true,
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "binding-a-binding-name-that-is-long-enough-to-exceed-t-e076c5d0",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "my-secret",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "read write almost podspecable",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				ReadOnly: &readWrite,
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/bindings",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "binding-db",
+													MountPath: "/bindings/db",
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "binding-db",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "my-secret",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "default mode almost podspecable",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				DefaultMode: &defaultMode,
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/bindings",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "binding-db",
+													MountPath: "/bindings/db",
+													ReadOnly:  true,
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "binding-db",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName:  "my-secret",
+													DefaultMode: &defaultMode,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "sub path almost podspecable",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "my-secret",
+				},
+			},
+			options: BindOptions{
+				SubPath: "data",
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/bindings",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "binding-db",
+													MountPath: "/bindings/db",
+													ReadOnly:  true,
+													SubPath:   "data",
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "binding-db",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "my-secret",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "user owned volume",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "user-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "user owned volume mount",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "malformed annotation",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "rebind with changed root",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				ServiceBindingRoot: "/custom/path",
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/custom/path/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/custom/path",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/custom/path/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "secret checksum",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				SecretChecksum: "my-digest",
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "my-digest",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "secret data checksum",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				SecretData: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("hunter2"),
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "sha256:1e2cc7a0e057664dde591476b2a446e72a6d74dca31487c12a6423a9d8cbecb1",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "rebind unchanged secret",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				SecretData: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("hunter2"),
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "sha256:1e2cc7a0e057664dde591476b2a446e72a6d74dca31487c12a6423a9d8cbecb1",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "sha256:1e2cc7a0e057664dde591476b2a446e72a6d74dca31487c12a6423a9d8cbecb1",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "rotated secret",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				SecretData: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("hunter2"),
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "sha256:stale",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "sha256:1e2cc7a0e057664dde591476b2a446e72a6d74dca31487c12a6423a9d8cbecb1",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "checksum removed",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "sha256:stale",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "skip conflicting volume",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				ConflictPolicy: ConflictPolicySkip,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "user-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"env":{"hello":["SERVICE_BINDING_ROOT"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "user-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "take over conflicting volume",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				ConflictPolicy: ConflictPolicyTakeOver,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "user-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "conflicting mount path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings/db",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "skip conflicting mount path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				ConflictPolicy: ConflictPolicySkip,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings/db",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings/db",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "take over conflicting mount path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				ConflictPolicy: ConflictPolicyTakeOver,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings/db",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "shadowed mount path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "take over shadowed mount path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				ConflictPolicy: ConflictPolicyTakeOver,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "skip shadowed mount path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				ConflictPolicy: ConflictPolicySkip,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "shadowing mount path",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings/db/config",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "nested bindings",
+			binding: Binding{
+				Name: "db/replica",
+				Secret: corev1.LocalObjectReference{
+					Name: "replica-secret",
+				},
+			},
+			options: BindOptions{
+				ConflictPolicy: ConflictPolicyTakeOver,
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "skip sidecar containers",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+								},
+								{
+									Name: "istio-init",
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"],"init-hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"],"init-hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name:         "istio-init",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name:         "istio-proxy",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "override default exclusions",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Selection: &ContainerSelection{},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+								},
+								{
+									Name: "istio-init",
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"],"init-hello":["SERVICE_BINDING_ROOT"],"istio-init":["SERVICE_BINDING_ROOT"],"istio-proxy":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"],"init-hello":["/bindings/db"],"istio-init":["/bindings/db"],"istio-proxy":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name: "istio-init",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name: "istio-proxy",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "containers by name",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Containers: []string{"hello"},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+								},
+								{
+									Name: "istio-init",
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name:         "init-hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+								{
+									Name:         "istio-init",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name:         "istio-proxy",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "exclude init containers",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Selection: &ContainerSelection{
+					Init: ContainerSelector{
+						Exclude: []string{"*"},
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+								},
+								{
+									Name: "istio-init",
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"],"istio-proxy":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"],"istio-proxy":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name:         "init-hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+								{
+									Name:         "istio-init",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name: "istio-proxy",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "include by regexp",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Selection: &ContainerSelection{
+					Init: ContainerSelector{
+						Include: []string{"regexp:^istio-"},
+					},
+					App: ContainerSelector{
+						Include: []string{"hel*"},
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+								},
+								{
+									Name: "istio-init",
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"],"istio-init":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"],"istio-init":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name:         "init-hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+								{
+									Name: "istio-init",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name:         "istio-proxy",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "deselected container released",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Containers: []string{"other"},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db"}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env:  []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "select by role",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Selection: &ContainerSelection{
+					Roles: []string{RoleInit},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+								},
+								{
+									Name: "istio-init",
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"init-hello":["SERVICE_BINDING_ROOT"],"istio-init":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"init-hello":["/bindings/db"],"istio-init":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+								{
+									Name: "istio-init",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:         "hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+								{
+									Name:         "istio-proxy",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid container pattern",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Selection: &ContainerSelection{
+					App: ContainerSelector{
+						Include: []string{"["},
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "init-hello",
+								},
+								{
+									Name: "istio-init",
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "binding label",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				LabelPrefix: "bindings.example.com/",
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app": "shop",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app":                     "shop",
+								"bindings.example.com/db": "true",
+							},
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]},"label":"bindings.example.com/db"}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "existing label untouched",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{
+				LabelPrefix: "bindings.example.com/",
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app":                     "shop",
+								"bindings.example.com/db": "false",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app":                     "shop",
+								"bindings.example.com/db": "false",
+							},
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "binding label removed",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			options: BindOptions{},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app":                     "shop",
+								"bindings.example.com/db": "true",
+							},
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]},"label":"bindings.example.com/db"}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app": "shop",
+							},
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "env projection",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Type: "postgresql",
+				Env: []EnvMapping{
+					{Name: "DB_HOST", Key: "host"},
+					{Name: "DB_TYPE", Key: "type"},
+					{Name: "DB_PROVIDER", Key: "provider"},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "DB_URL",
+											Value: "postgres://$(DB_HOST)",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:          `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT","DB_HOST","DB_TYPE","DB_PROVIDER"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								TypeAnnotationPrefix + "db": "postgresql",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name: "DB_HOST",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{
+														Name: "db-secret",
+													},
+													Key: "host",
+												},
+											},
+										},
+										{
+											Name:  "DB_URL",
+											Value: "postgres://$(DB_HOST)",
+										},
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+										{
+											Name:  "DB_TYPE",
+											Value: "postgresql",
+										},
+										{
+											Name: "DB_PROVIDER",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{
+														Name: "db-secret",
+													},
+													Key: "provider",
+												},
+											},
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Projected: &corev1.ProjectedVolumeSource{
+											Sources: []corev1.VolumeProjection{
+												{
+													Secret: &corev1.SecretProjection{
+														LocalObjectReference: corev1.LocalObjectReference{
+															Name: "db-secret",
+														},
+													},
+												},
+												{
+													DownwardAPI: &corev1.DownwardAPIProjection{
+														Items: []corev1.DownwardAPIVolumeFile{
+															{
+																Path: "type",
+																FieldRef: &corev1.ObjectFieldSelector{
+																	FieldPath: "metadata.annotations['type.meta-binding.scothis.github.io/db']",
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "type and provider projected into the volume",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Type:     "postgresql",
+				Provider: "bitnami",
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								TypeAnnotationPrefix + "db":     "postgresql",
+								ProviderAnnotationPrefix + "db": "bitnami",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Projected: &corev1.ProjectedVolumeSource{
+											Sources: []corev1.VolumeProjection{
+												{
+													Secret: &corev1.SecretProjection{
+														LocalObjectReference: corev1.LocalObjectReference{
+															Name: "db-secret",
+														},
+													},
+												},
+												{
+													DownwardAPI: &corev1.DownwardAPIProjection{
+														Items: []corev1.DownwardAPIVolumeFile{
+															{
+																Path: "type",
+																FieldRef: &corev1.ObjectFieldSelector{
+																	FieldPath: "metadata.annotations['type.meta-binding.scothis.github.io/db']",
+																},
+															},
+															{
+																Path: "provider",
+																FieldRef: &corev1.ObjectFieldSelector{
+																	FieldPath: "metadata.annotations['provider.meta-binding.scothis.github.io/db']",
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "type and provider no longer overridden",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								TypeAnnotationPrefix + "db":     "postgresql",
+								ProviderAnnotationPrefix + "db": "bitnami",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Projected: &corev1.ProjectedVolumeSource{
+											Sources: []corev1.VolumeProjection{
+												{
+													Secret: &corev1.SecretProjection{
+														LocalObjectReference: corev1.LocalObjectReference{
+															Name: "db-secret",
+														},
+													},
+												},
+												{
+													DownwardAPI: &corev1.DownwardAPIProjection{
+														Items: []corev1.DownwardAPIVolumeFile{
+															{
+																Path: "type",
+																FieldRef: &corev1.ObjectFieldSelector{
+																	FieldPath: "metadata.annotations['type.meta-binding.scothis.github.io/db']",
+																},
+															},
+															{
+																Path: "provider",
+																FieldRef: &corev1.ObjectFieldSelector{
+																	FieldPath: "metadata.annotations['provider.meta-binding.scothis.github.io/db']",
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "env projection updated in place",
+			binding: Binding{
+				Name: "db",
+				Type: "mysql",
+				Env: []EnvMapping{
+					{Name: "DB_TYPE", Key: "type"},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"env":{"hello":["SERVICE_BINDING_ROOT","DB_TYPE"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "DB_TYPE",
+											Value: "postgresql",
+										},
+										{
+											Name:  "USER",
+											Value: "hello",
+										},
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"env":{"hello":["SERVICE_BINDING_ROOT","DB_TYPE"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "DB_TYPE",
+											Value: "mysql",
+										},
+										{
+											Name:  "USER",
+											Value: "hello",
+										},
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "env projection conflicts with user env",
+			binding: Binding{
+				Name: "db",
+				Type: "mysql",
+				Env: []EnvMapping{
+					{Name: "DB_TYPE", Key: "type"},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "DB_TYPE",
+											Value: "postgresql",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "env projection without secret",
+			binding: Binding{
+				Name: "db",
+				Env: []EnvMapping{
+					{Name: "DB_HOST", Key: "host"},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name:    "invalid container jsonpath",
+			binding: Binding{Name: "db"},
+			mapping: PodMapping{
+				Containers: []ContainerMapping{
+					{
+						Path: "[",
+					},
+				},
+			},
+			seed:        &appsv1.Deployment{},
+			expectedErr: true,
+		},
+		{
+			name:        "conversion error",
+			binding:     Binding{Name: "db"},
+			mapping:     PodMapping{},
+			seed:        &BadMarshalJSON{},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.seed.DeepCopyObject()
+			m := &c.mapping
+			m.Default()
+			_, err := c.binding.Bind(actual, m, c.options)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("Bind() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Bind() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestBinding_Unbind(t *testing.T) {
+	tests := []struct {
+		name        string
+		binding     Binding
+		mapping     PodMapping
+		seed        runtime.Object
+		expected    runtime.Object
+		expectedErr bool
+	}{
+		{
+			name: "unbind",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env:  []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "shared env retained",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"cache":{"volume":"binding-cache","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/cache"]}},"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+										{
+											Name:      "binding-cache",
+											MountPath: "/bindings/cache",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+								{
+									Name: "binding-cache",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "cache-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"cache":{"volume":"binding-cache","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/cache"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-cache",
+											MountPath: "/bindings/cache",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-cache",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "cache-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "user env retained",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "not bound",
+			binding: Binding{
+				Name: "cache",
+				Secret: corev1.LocalObjectReference{
+					Name: "cache-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unbind removes checksum",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								ChecksumAnnotationPrefix + "db": "my-digest",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:         "hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unbind removes type and provider",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation:              `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+								TypeAnnotationPrefix + "db":     "postgresql",
+								ProviderAnnotationPrefix + "db": "bitnami",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Projected: &corev1.ProjectedVolumeSource{
+											Sources: []corev1.VolumeProjection{
+												{
+													Secret: &corev1.SecretProjection{
+														LocalObjectReference: corev1.LocalObjectReference{
+															Name: "db-secret",
+														},
+													},
+												},
+												{
+													DownwardAPI: &corev1.DownwardAPIProjection{
+														Items: []corev1.DownwardAPIVolumeFile{
+															{
+																Path: "type",
+																FieldRef: &corev1.ObjectFieldSelector{
+																	FieldPath: "metadata.annotations['type.meta-binding.scothis.github.io/db']",
+																},
+															},
+															{
+																Path: "provider",
+																FieldRef: &corev1.ObjectFieldSelector{
+																	FieldPath: "metadata.annotations['provider.meta-binding.scothis.github.io/db']",
+																},
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:         "hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unbind removes label",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app":                     "shop",
+								"bindings.example.com/db": "true",
+							},
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]},"label":"bindings.example.com/db"}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app": "shop",
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app": "shop",
+							},
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:         "hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "malformed annotation",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.seed.DeepCopyObject()
+			m := &c.mapping
+			m.Default()
+			_, err := c.binding.Unbind(actual, m)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("Unbind() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Unbind() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestBindAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		bindings    []Binding
+		options     BindOptions
+		mapping     PodMapping
+		seed        runtime.Object
+		expected    runtime.Object
+		expectedErr bool
+	}{
+		{
+			name: "multiple bindings",
+			bindings: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+				Binding{
+					Name: "cache",
+					Secret: corev1.LocalObjectReference{
+						Name: "cache-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"cache":{"volume":"binding-cache","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/cache"]}},"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-cache",
+											MountPath: "/bindings/cache",
+											ReadOnly:  true,
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-cache",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "cache-secret",
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple bindings almost podspecable",
+			bindings: []Binding{
+				Binding{
+					Name: "cache",
+					Secret: corev1.LocalObjectReference{
+						Name: "cache-secret",
+					},
+				},
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+			},
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			seed: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &batchv1.CronJob{
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: metav1.ObjectMeta{
+									Annotations: map[string]string{
+										BindingsAnnotation: `{"cache":{"volume":"binding-cache","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/cache"]}},"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+									},
+								},
+								Spec: corev1.PodSpec{
+									Containers: []corev1.Container{
+										{
+											Name: "hello",
+											Env: []corev1.EnvVar{
+												{
+													Name:  "SERVICE_BINDING_ROOT",
+													Value: "/bindings",
+												},
+											},
+											VolumeMounts: []corev1.VolumeMount{
+												{
+													Name:      "binding-cache",
+													MountPath: "/bindings/cache",
+													ReadOnly:  true,
+												},
+												{
+													Name:      "binding-db",
+													MountPath: "/bindings/db",
+													ReadOnly:  true,
+												},
+											},
+										},
+									},
+									Volumes: []corev1.Volume{
+										{
+											Name: "binding-cache",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "cache-secret",
+												},
+											},
+										},
+										{
+											Name: "binding-db",
+											VolumeSource: corev1.VolumeSource{
+												Secret: &corev1.SecretVolumeSource{
+													SecretName: "db-secret",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "no bindings",
+			bindings: []Binding{},
+			mapping:  PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:         "hello",
+									Env:          []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{},
+								},
+							},
+							Volumes: []corev1.Volume{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate binding name",
+			bindings: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "other-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid binding name",
+			bindings: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+				Binding{
+					Name: "../etc",
+					Secret: corev1.LocalObjectReference{
+						Name: "other-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "conflicting volume names",
+			bindings: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+				Binding{
+					Name: "cache",
+					Secret: corev1.LocalObjectReference{
+						Name: "cache-secret",
+					},
+				},
+			},
+			options: BindOptions{
+				VolumeName: func(b *Binding) string {
+					return "binding"
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.seed.DeepCopyObject()
+			m := &c.mapping
+			m.Default()
+			_, err := BindAll(actual, m, c.bindings, c.options)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("BindAll() expected err: %v", err)
+			}
+			if c.expectedErr {
+				if diff := cmp.Diff(c.seed, actual); diff != "" {
+					t.Errorf("BindAll() unexpected mutation (-expected, +actual): %s", diff)
+				}
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("BindAll() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+var (
+	_ runtime.Object = (*BadMarshalJSON)(nil)
+)
+
+type BadMarshalJSON struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+func (r *BadMarshalJSON) MarshalJSON() ([]byte, error)   { return nil, fmt.Errorf("bad json marshal") }
+func (r *BadMarshalJSON) DeepCopyObject() runtime.Object { return r }
+
+func TestDefaultVolumeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		binding  Binding
+		expected string
+	}{
+		{
+			name:     "short name",
+			binding:  Binding{Name: "db"},
+			expected: "binding-db",
+		},
+		{
+			name:     "max length",
+			binding:  Binding{Name: "a-binding-name-that-is-exactly-long-enough-for-a-dns-la"},
+			expected: "binding-a-binding-name-that-is-exactly-long-enough-for-a-dns-la",
+		},
+		{
+			name:     "hashed",
+			binding:  Binding{Name: "a-binding-name-that-is-long-enough-to-exceed-the-dns-label-limit"},
+			expected: "binding-a-binding-name-that-is-long-enough-to-exceed-t-e076c5d0",
+		},
+		{
+			name:     "invalid characters",
+			binding:  Binding{Name: "DB_Main"},
+			expected: "binding-18ccb3e6",
+		},
+		{
+			name:     "dots",
+			binding:  Binding{Name: "db.main"},
+			expected: "binding-7d98d358",
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := DefaultVolumeName(&c.binding)
+			if len(actual) > 63 {
+				t.Errorf("DefaultVolumeName() too long: %d", len(actual))
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("DefaultVolumeName() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
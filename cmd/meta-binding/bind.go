@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func runBind(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("bind", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	bindings := bindingFlags{}
+	fs.Var(&bindings, "binding", "binding as `NAME=SECRET`, may be repeated")
+	bindingsFile := fs.String("bindings", "", "`file` holding a YAML list of bindings")
+	mappingFile := fs.String("mapping", "", "`file` holding a YAML list of mappings for additional kinds")
+	opts := binding.BindOptions{}
+	fs.StringVar(&opts.ServiceBindingRoot, "service-binding-root", "", "directory bindings are mounted within (default \"/bindings\")")
+	conflictPolicy := fs.String("conflict-policy", string(binding.ConflictPolicyFail), "how to treat conflicting volumes and mounts: Fail, Skip or TakeOver")
+	labelPrefix := fs.String("label-prefix", "", "label bound pods with the prefix followed by the binding name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *bindingsFile != "" {
+		b, err := loadBindings(*bindingsFile)
+		if err != nil {
+			return err
+		}
+		bindings = append(bindings, b...)
+	}
+	if len(bindings) == 0 {
+		return fmt.Errorf("at least one binding is required")
+	}
+	opts.ConflictPolicy = binding.ConflictPolicy(*conflictPolicy)
+	if err := validateConflictPolicy(opts.ConflictPolicy); err != nil {
+		return err
+	}
+	opts.LabelPrefix = *labelPrefix
+	registry, err := loadRegistry(*mappingFile)
+	if err != nil {
+		return err
+	}
+
+	docs, err := readManifests(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	if err := bindDocuments(docs, registry, bindings, opts, nil); err != nil {
+		return err
+	}
+	return writeDocuments(stdout, docs)
+}
+
+// bindDocuments binds each selected document of a kind known to the registry. Every document
+// is selected if selects is nil. Other documents are left untouched.
+func bindDocuments(docs []*document, registry binding.Registry, bindings []binding.Binding, opts binding.BindOptions, selects func(*unstructured.Unstructured) (bool, error)) error {
+	for i, d := range docs {
+		if d.object == nil {
+			continue
+		}
+		if selects != nil {
+			selected, err := selects(d.object)
+			if err != nil {
+				return err
+			}
+			if !selected {
+				continue
+			}
+		}
+		gvk := d.object.GroupVersionKind()
+		m, ok := registry.Lookup(gvk)
+		if !ok {
+			continue
+		}
+		result, err := binding.BindAll(d.object, m, bindings, opts)
+		if err != nil {
+			return fmt.Errorf("document %d, %s %q: %w", i+1, gvk.Kind, d.object.GetName(), err)
+		}
+		d.modified = d.modified || result.Changed
+	}
+	return nil
+}
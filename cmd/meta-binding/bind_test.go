@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunBind(t *testing.T) {
+	dir := t.TempDir()
+	bindingsFile := filepath.Join(dir, "bindings.yaml")
+	if err := ioutil.WriteFile(bindingsFile, []byte(`
+- name: cache
+  secret:
+    name: cache-secret
+  containers:
+  - worker
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mappingFile := filepath.Join(dir, "mapping.yaml")
+	if err := ioutil.WriteFile(mappingFile, []byte(`
+- group: example.com
+  kind: Widget
+  mapping:
+    annotations: /spec/annotations
+    labels: /spec/labels
+    containers:
+    - path: .spec.workers[*]
+      name: /name
+    volumes: /spec/volumes
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidMappingFile := filepath.Join(dir, "invalid-mapping.yaml")
+	if err := ioutil.WriteFile(invalidMappingFile, []byte(`
+- kind: Widget
+  mapping:
+    volumes: spec/volumes
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		stdin       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "binds workloads in order",
+			args: []string{"bind", "--binding", "db=db-secret"},
+			stdin: `# leading comment
+---
+apiVersion: v1
+kind: ConfigMap   # not a workload
+metadata:
+  name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: hello
+        image: hello:latest
+---
+{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "hello"}]}}
+`,
+			expected: `# leading comment
+---
+apiVersion: v1
+kind: ConfigMap   # not a workload
+metadata:
+  name: config
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: hello
+        image: hello:latest
+        env:
+        - name: SERVICE_BINDING_ROOT
+          value: /bindings
+        volumeMounts:
+        - mountPath: /bindings/db
+          name: binding-db
+          readOnly: true
+      volumes:
+      - name: binding-db
+        secret:
+          secretName: db-secret
+    metadata:
+      annotations:
+        meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}'
+---
+{
+  "apiVersion": "v1",
+  "kind": "Pod",
+  "metadata": {
+    "annotations": {
+      "meta-binding.scothis.github.io/bindings": "{\"db\":{\"volume\":\"binding-db\",\"env\":{\"hello\":[\"SERVICE_BINDING_ROOT\"]},\"volumeMounts\":{\"hello\":[\"/bindings/db\"]}}}"
+    }
+  },
+  "spec": {
+    "containers": [
+      {
+        "env": [
+          {
+            "name": "SERVICE_BINDING_ROOT",
+            "value": "/bindings"
+          }
+        ],
+        "name": "hello",
+        "volumeMounts": [
+          {
+            "mountPath": "/bindings/db",
+            "name": "binding-db",
+            "readOnly": true
+          }
+        ]
+      }
+    ],
+    "volumes": [
+      {
+        "name": "binding-db",
+        "secret": {
+          "secretName": "db-secret"
+        }
+      }
+    ]
+  }
+}
+`,
+		},
+		{
+			name: "already bound workloads are untouched",
+			args: []string{"bind", "--binding", "db=db-secret"},
+			stdin: `apiVersion: v1
+kind: Pod
+metadata:
+  annotations:
+    meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}'
+spec:
+  containers:
+  - name: hello   # comments are retained
+    env:
+    - name: SERVICE_BINDING_ROOT
+      value: /bindings
+    volumeMounts:
+    - name: binding-db
+      mountPath: /bindings/db
+      readOnly: true
+  volumes:
+  - name: binding-db
+    secret:
+      secretName: db-secret
+`,
+			expected: `apiVersion: v1
+kind: Pod
+metadata:
+  annotations:
+    meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}'
+spec:
+  containers:
+  - name: hello   # comments are retained
+    env:
+    - name: SERVICE_BINDING_ROOT
+      value: /bindings
+    volumeMounts:
+    - name: binding-db
+      mountPath: /bindings/db
+      readOnly: true
+  volumes:
+  - name: binding-db
+    secret:
+      secretName: db-secret
+`,
+		},
+		{
+			name: "custom mapping and bindings file",
+			args: []string{"bind", "--mapping", mappingFile, "--bindings", bindingsFile},
+			stdin: `apiVersion: example.com/v1
+kind: Widget
+spec:
+  workers:
+  - name: worker
+`,
+			expected: `apiVersion: example.com/v1
+kind: Widget
+spec:
+  workers:
+  - name: worker
+    env:
+    - name: SERVICE_BINDING_ROOT
+      value: /bindings
+    volumeMounts:
+    - mountPath: /bindings/cache
+      name: binding-cache
+      readOnly: true
+  annotations:
+    meta-binding.scothis.github.io/bindings: '{"cache":{"volume":"binding-cache","env":{"worker":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"worker":["/bindings/cache"]}}}'
+  volumes:
+  - name: binding-cache
+    secret:
+      secretName: cache-secret
+`,
+		},
+		{
+			name: "unknown kinds pass through",
+			args: []string{"bind", "--binding", "db=db-secret"},
+			stdin: `apiVersion: example.com/v1
+kind: Widget
+spec:
+  workers:
+  - name: worker
+`,
+			expected: `apiVersion: example.com/v1
+kind: Widget
+spec:
+  workers:
+  - name: worker
+`,
+		},
+		{
+			name:        "invalid mapping",
+			args:        []string{"bind", "--binding", "db=db-secret", "--mapping", invalidMappingFile},
+			expectedErr: "mapping 0 for Widget: volumes: Invalid value",
+		},
+		{
+			name:        "missing bindings",
+			args:        []string{"bind"},
+			expectedErr: "at least one binding is required",
+		},
+		{
+			name:        "malformed binding",
+			args:        []string{"bind", "--binding", "db"},
+			expectedErr: `expected NAME=SECRET, got "db"`,
+		},
+		{
+			name: "bind error",
+			args: []string{"bind", "--binding", "db=db-secret"},
+			stdin: `apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+spec:
+  containers:
+  - name: hello
+  volumes:
+  - name: binding-db
+`,
+			expectedErr: `document 1, Pod "hello": binding "db"`,
+		},
+		{
+			name:        "unknown command",
+			args:        []string{"unbind"},
+			expectedErr: `unknown command "unbind"`,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			err := run(c.args, strings.NewReader(c.stdin), stdout, stderr)
+
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Errorf("run() expected err containing %q, got %v", c.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, stdout.String()); diff != "" {
+				t.Errorf("run() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// bindingFlags collects bindings given as `NAME=SECRET`.
+type bindingFlags []binding.Binding
+
+func (f *bindingFlags) String() string {
+	values := make([]string, len(*f))
+	for i, b := range *f {
+		values[i] = fmt.Sprintf("%s=%s", b.Name, b.Secret.Name)
+	}
+	return strings.Join(values, ",")
+}
+
+func (f *bindingFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("expected NAME=SECRET, got %q", value)
+	}
+	*f = append(*f, binding.Binding{
+		Name: parts[0],
+		Secret: corev1.LocalObjectReference{
+			Name: parts[1],
+		},
+	})
+	return nil
+}
+
+// mappingEntry registers a PodMapping for a kind of workload.
+type mappingEntry struct {
+	// Group of the workload kind. Empty for the core group.
+	Group string `json:"group,omitempty"`
+	// Kind of the workload.
+	Kind string `json:"kind"`
+	// Mapping locates the pod template within the workload. Fields are named as they are in
+	// Go, the first letter may be lower case.
+	Mapping binding.PodMapping `json:"mapping"`
+}
+
+// loadBindings reads a YAML or JSON list of bindings from the file.
+func loadBindings(file string) ([]binding.Binding, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	bindings := []binding.Binding{}
+	if err := yaml.Unmarshal(data, &bindings); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return bindings, nil
+}
+
+// loadRegistry returns the default registry extended with the mappings listed in the file. An
+// empty file name returns the default registry.
+func loadRegistry(file string) (binding.Registry, error) {
+	registry := binding.DefaultRegistry()
+	if file == "" {
+		return registry, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	entries := []mappingEntry{}
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := registerMappings(registry, entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return registry, nil
+}
+
+// registerMappings validates and adds the mappings to the registry, replacing any existing
+// mapping for the same kind.
+func registerMappings(registry binding.Registry, entries []mappingEntry) error {
+	for i, entry := range entries {
+		if entry.Kind == "" {
+			return fmt.Errorf("mapping %d: kind is required", i)
+		}
+		m := entry.Mapping
+		m.Default()
+		if errs := m.Validate(); len(errs) != 0 {
+			return fmt.Errorf("mapping %d for %s: %w", i, entry.Kind, errs.ToAggregate())
+		}
+		registry[schema.GroupKind{Group: entry.Group, Kind: entry.Kind}] = entry.Mapping
+	}
+	return nil
+}
+
+// bindConfig configures which workloads are bound, and how, for the KRM function and
+// post-renderer.
+type bindConfig struct {
+	// Bindings to apply to each selected workload.
+	Bindings []binding.Binding `json:"bindings"`
+	// Targets selects the workloads to bind. If empty, every workload of a kind with a mapping
+	// is bound.
+	Targets []selector `json:"targets,omitempty"`
+	// Mappings for kinds that are not well known Kubernetes workloads.
+	Mappings []mappingEntry `json:"mappings,omitempty"`
+	// ServiceBindingRoot is the directory bindings are mounted within.
+	ServiceBindingRoot string `json:"serviceBindingRoot,omitempty"`
+	// ConflictPolicy is how conflicting volumes and volume mounts are treated.
+	ConflictPolicy binding.ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// LabelPrefix labels bound pods with the prefix followed by the binding name.
+	LabelPrefix string `json:"labelPrefix,omitempty"`
+}
+
+func (c *bindConfig) options() (binding.BindOptions, error) {
+	if err := validateConflictPolicy(c.ConflictPolicy); err != nil {
+		return binding.BindOptions{}, err
+	}
+	return binding.BindOptions{
+		ServiceBindingRoot: c.ServiceBindingRoot,
+		ConflictPolicy:     c.ConflictPolicy,
+		LabelPrefix:        c.LabelPrefix,
+	}, nil
+}
+
+func (c *bindConfig) registry() (binding.Registry, error) {
+	registry := binding.DefaultRegistry()
+	if err := registerMappings(registry, c.Mappings); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// selects returns true if any of the targets selects the object, or there are no targets.
+func (c *bindConfig) selects(obj *unstructured.Unstructured) (bool, error) {
+	if len(c.Targets) == 0 {
+		return true, nil
+	}
+	for i := range c.Targets {
+		selected, err := c.Targets[i].matches(obj)
+		if err != nil || selected {
+			return selected, err
+		}
+	}
+	return false, nil
+}
+
+// selector matches workloads by their kind, name and labels. Empty fields match everything.
+type selector struct {
+	// Group of the workload kind. Empty matches any group.
+	Group string `json:"group,omitempty"`
+	// Kind of the workload.
+	Kind string `json:"kind,omitempty"`
+	// Name of the workload.
+	Name string `json:"name,omitempty"`
+	// Namespace of the workload.
+	Namespace string `json:"namespace,omitempty"`
+	// Labels is a label selector, such as `app=hello,tier!=cache`, matched against the
+	// workload's labels.
+	Labels string `json:"labels,omitempty"`
+}
+
+func (s *selector) matches(obj *unstructured.Unstructured) (bool, error) {
+	gvk := obj.GroupVersionKind()
+	if (s.Group != "" && s.Group != gvk.Group) || (s.Kind != "" && s.Kind != gvk.Kind) {
+		return false, nil
+	}
+	if (s.Name != "" && s.Name != obj.GetName()) || (s.Namespace != "" && s.Namespace != obj.GetNamespace()) {
+		return false, nil
+	}
+	if s.Labels == "" {
+		return true, nil
+	}
+	ls, err := labels.Parse(s.Labels)
+	if err != nil {
+		return false, fmt.Errorf("invalid label selector %q: %w", s.Labels, err)
+	}
+	return ls.Matches(labels.Set(obj.GetLabels())), nil
+}
+
+func validateConflictPolicy(policy binding.ConflictPolicy) error {
+	switch policy {
+	case "", binding.ConflictPolicyFail, binding.ConflictPolicySkip, binding.ConflictPolicyTakeOver:
+		return nil
+	}
+	return fmt.Errorf("unknown conflict policy %q", policy)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// inspection is the MetaPodTemplate of a workload, as seen by the binding engine.
+type inspection struct {
+	APIVersion  string               `json:"apiVersion"`
+	Kind        string               `json:"kind"`
+	Name        string               `json:"name,omitempty"`
+	Pointers    podPointers          `json:"pointers"`
+	Annotations map[string]string    `json:"annotations"`
+	Labels      map[string]string    `json:"labels"`
+	Containers  []inspectedContainer `json:"containers"`
+	Volumes     []corev1.Volume      `json:"volumes"`
+}
+
+type podPointers struct {
+	Annotations string `json:"annotations"`
+	Labels      string `json:"labels,omitempty"`
+	Volumes     string `json:"volumes"`
+}
+
+type inspectedContainer struct {
+	Name         string               `json:"name"`
+	Role         string               `json:"role,omitempty"`
+	Origin       containerOrigin      `json:"origin"`
+	Pointers     containerPointers    `json:"pointers"`
+	Env          []corev1.EnvVar      `json:"env"`
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts"`
+}
+
+// containerOrigin describes where a container was discovered.
+type containerOrigin struct {
+	// Mapping is the index of the ContainerMapping that discovered the container.
+	Mapping int `json:"mapping"`
+	// Path is the JSONPath query of the ContainerMapping.
+	Path string `json:"path"`
+	// Location is a JSON Pointer to the container within the workload.
+	Location string `json:"location"`
+}
+
+// containerPointers are the container's pointers resolved from the root of the workload.
+type containerPointers struct {
+	Name         string `json:"name,omitempty"`
+	Env          string `json:"env"`
+	VolumeMounts string `json:"volumeMounts"`
+}
+
+// explanation is the trace of a mapping evaluated against a workload.
+type explanation struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name,omitempty"`
+	*binding.Explanation
+}
+
+// runInspect prints the MetaPodTemplate of each workload within the manifests.
+func runInspect(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	return runMappingCommand("inspect", args, stdin, stdout, stderr, func(obj *unstructured.Unstructured, m *binding.PodMapping) (interface{}, error) {
+		mpt, err := m.ToMeta(obj)
+		if err != nil {
+			return nil, err
+		}
+		labels := mpt.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		i := inspection{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Pointers: podPointers{
+				Annotations: m.Annotations,
+				Labels:      m.Labels,
+				Volumes:     m.Volumes,
+			},
+			Annotations: mpt.Annotations,
+			Labels:      labels,
+			Containers:  make([]inspectedContainer, len(mpt.Containers)),
+			Volumes:     mpt.Volumes,
+		}
+		for j, c := range mpt.Containers {
+			cm := m.Containers[c.Mapping]
+			ic := inspectedContainer{
+				Name: c.Name,
+				Role: c.Role,
+				Origin: containerOrigin{
+					Mapping:  c.Mapping,
+					Path:     cm.Path,
+					Location: c.Location,
+				},
+				Pointers: containerPointers{
+					Env:          c.Location + cm.Env,
+					VolumeMounts: c.Location + cm.VolumeMounts,
+				},
+				Env:          c.Env,
+				VolumeMounts: c.VolumeMounts,
+			}
+			if cm.Name != "" {
+				ic.Pointers.Name = c.Location + cm.Name
+			}
+			i.Containers[j] = ic
+		}
+		return i, nil
+	})
+}
+
+// runExplain prints how the mapping resolves against each workload within the manifests.
+func runExplain(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	return runMappingCommand("explain", args, stdin, stdout, stderr, func(obj *unstructured.Unstructured, m *binding.PodMapping) (interface{}, error) {
+		e, err := m.Explain(obj)
+		if err != nil {
+			return nil, err
+		}
+		return explanation{
+			APIVersion:  obj.GetAPIVersion(),
+			Kind:        obj.GetKind(),
+			Name:        obj.GetName(),
+			Explanation: e,
+		}, nil
+	})
+}
+
+// runMappingCommand reports on each document of the manifests with a mapping, printing the
+// reports as a YAML stream or a JSON array. Documents without a mapping are noted on stderr.
+func runMappingCommand(name string, args []string, stdin io.Reader, stdout, stderr io.Writer, report func(*unstructured.Unstructured, *binding.PodMapping) (interface{}, error)) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	mappingFile := fs.String("mapping", "", "`file` holding a YAML list of mappings for additional kinds")
+	podMappingFile := fs.String("pod-mapping", "", "`file` holding a single mapping used for every document, regardless of kind")
+	output := fs.String("output", "yaml", "output format: yaml or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output != "yaml" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	registry, err := loadRegistry(*mappingFile)
+	if err != nil {
+		return err
+	}
+	var podMapping *binding.PodMapping
+	if *podMappingFile != "" {
+		if podMapping, err = loadPodMapping(*podMappingFile); err != nil {
+			return err
+		}
+	}
+
+	docs, err := readManifests(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	reports := []interface{}{}
+	for i, d := range docs {
+		if d.object == nil {
+			continue
+		}
+		m := podMapping
+		if m == nil {
+			var ok bool
+			if m, ok = registry.Lookup(d.object.GroupVersionKind()); !ok {
+				fmt.Fprintf(stderr, "document %d, %s %q: skipped, no mapping for kind\n", i+1, d.object.GetKind(), d.object.GetName())
+				continue
+			}
+		}
+		r, err := report(d.object, m)
+		if err != nil {
+			return fmt.Errorf("document %d, %s %q: %w", i+1, d.object.GetKind(), d.object.GetName(), err)
+		}
+		reports = append(reports, r)
+	}
+
+	if *output == "json" {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(stdout, "%s\n", data)
+		return err
+	}
+	for i, r := range reports {
+		data, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if i != 0 {
+			if _, err := io.WriteString(stdout, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := stdout.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPodMapping reads a single PodMapping from a YAML or JSON file.
+func loadPodMapping(file string) (*binding.PodMapping, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m := &binding.PodMapping{}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	m.Default()
+	if errs := m.Validate(); len(errs) != 0 {
+		return nil, fmt.Errorf("%s: %w", file, errs.ToAggregate())
+	}
+	return m, nil
+}
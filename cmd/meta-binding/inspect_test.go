@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunInspect(t *testing.T) {
+	dir := t.TempDir()
+	podMappingFile := filepath.Join(dir, "mapping.yaml")
+	if err := ioutil.WriteFile(podMappingFile, []byte(`
+annotations: /spec/template/metadata/annotations
+labels: /spec/template/metadata/labels
+containers:
+- path: .spec.template.spec.workers[*]
+  name: /name
+volumes: /spec/template/spec/volumes
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidPodMappingFile := filepath.Join(dir, "invalid.yaml")
+	if err := ioutil.WriteFile(invalidPodMappingFile, []byte(`
+containers:
+- path: .spec.containers[*]
+  env: env
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	knativeService := `apiVersion: serving.knative.dev/v1
+kind: Service
+metadata:
+  name: hello
+spec:
+  template:
+    metadata:
+      labels:
+        app: hello
+    spec:
+      containers:
+      - name: user
+        image: hello
+        env:
+        - name: TARGET
+          value: world
+`
+
+	tests := []struct {
+		name           string
+		args           []string
+		stdin          string
+		expected       string
+		expectedStderr string
+		expectedErr    string
+	}{
+		{
+			name:  "knative service",
+			args:  []string{"inspect"},
+			stdin: knativeService,
+			expected: `annotations: {}
+apiVersion: serving.knative.dev/v1
+containers:
+- env:
+  - name: TARGET
+    value: world
+  name: user
+  origin:
+    location: /spec/template/spec/containers/0
+    mapping: 1
+    path: .spec.template.spec.containers[*]
+  pointers:
+    env: /spec/template/spec/containers/0/env
+    name: /spec/template/spec/containers/0/name
+    volumeMounts: /spec/template/spec/containers/0/volumeMounts
+  role: app
+  volumeMounts: []
+kind: Service
+labels:
+  app: hello
+name: hello
+pointers:
+  annotations: /spec/template/metadata/annotations
+  labels: /spec/template/metadata/labels
+  volumes: /spec/template/spec/volumes
+volumes: []
+`,
+		},
+		{
+			name: "cron job as json, skipping config maps",
+			args: []string{"inspect", "--output", "json"},
+			stdin: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers:
+          - name: setup
+            image: setup
+          containers:
+          - name: job
+            image: job
+`,
+			expected: `[
+  {
+    "apiVersion": "batch/v1",
+    "kind": "CronJob",
+    "name": "nightly",
+    "pointers": {
+      "annotations": "/spec/jobTemplate/spec/template/metadata/annotations",
+      "labels": "/spec/jobTemplate/spec/template/metadata/labels",
+      "volumes": "/spec/jobTemplate/spec/template/spec/volumes"
+    },
+    "annotations": {},
+    "labels": {},
+    "containers": [
+      {
+        "name": "setup",
+        "role": "init",
+        "origin": {
+          "mapping": 0,
+          "path": ".spec.jobTemplate.spec.template.spec.initContainers[*]",
+          "location": "/spec/jobTemplate/spec/template/spec/initContainers/0"
+        },
+        "pointers": {
+          "name": "/spec/jobTemplate/spec/template/spec/initContainers/0/name",
+          "env": "/spec/jobTemplate/spec/template/spec/initContainers/0/env",
+          "volumeMounts": "/spec/jobTemplate/spec/template/spec/initContainers/0/volumeMounts"
+        },
+        "env": [],
+        "volumeMounts": []
+      },
+      {
+        "name": "job",
+        "role": "app",
+        "origin": {
+          "mapping": 1,
+          "path": ".spec.jobTemplate.spec.template.spec.containers[*]",
+          "location": "/spec/jobTemplate/spec/template/spec/containers/0"
+        },
+        "pointers": {
+          "name": "/spec/jobTemplate/spec/template/spec/containers/0/name",
+          "env": "/spec/jobTemplate/spec/template/spec/containers/0/env",
+          "volumeMounts": "/spec/jobTemplate/spec/template/spec/containers/0/volumeMounts"
+        },
+        "env": [],
+        "volumeMounts": []
+      }
+    ],
+    "volumes": []
+  }
+]
+`,
+			expectedStderr: "document 1, ConfigMap \"config\": skipped, no mapping for kind\n",
+		},
+		{
+			name: "supplied pod mapping",
+			args: []string{"inspect", "--pod-mapping", podMappingFile},
+			stdin: `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+spec:
+  template:
+    spec:
+      workers:
+      - name: worker
+        image: worker
+`,
+			expected: `annotations: {}
+apiVersion: example.com/v1
+containers:
+- env: []
+  name: worker
+  origin:
+    location: /spec/template/spec/workers/0
+    mapping: 0
+    path: .spec.template.spec.workers[*]
+  pointers:
+    env: /spec/template/spec/workers/0/env
+    name: /spec/template/spec/workers/0/name
+    volumeMounts: /spec/template/spec/workers/0/volumeMounts
+  volumeMounts: []
+kind: Widget
+labels: {}
+name: widget
+pointers:
+  annotations: /spec/template/metadata/annotations
+  labels: /spec/template/metadata/labels
+  volumes: /spec/template/spec/volumes
+volumes: []
+`,
+		},
+		{
+			name:  "explain",
+			args:  []string{"explain"},
+			stdin: knativeService,
+			expected: `annotations:
+  location: /spec/template/metadata/annotations
+  message: no value at "/spec/template/metadata/annotations"
+  pointer: /spec/template/metadata/annotations
+  status: Missing
+apiVersion: serving.knative.dev/v1
+containers:
+- matches: 0
+  message: initContainers is not found
+  nodes: []
+  path: .spec.template.spec.initContainers[*]
+- matches: 1
+  nodes:
+  - env:
+      location: /spec/template/spec/containers/0/env
+      pointer: /env
+      status: Resolved
+      value:
+      - name: TARGET
+        value: world
+    location: /spec/template/spec/containers/0
+    name:
+      location: /spec/template/spec/containers/0/name
+      pointer: /name
+      status: Resolved
+      value: user
+    volumeMounts:
+      location: /spec/template/spec/containers/0/volumeMounts
+      message: no value at "/spec/template/spec/containers/0/volumeMounts"
+      pointer: /volumeMounts
+      status: Missing
+  path: .spec.template.spec.containers[*]
+- matches: 0
+  message: ephemeralContainers is not found
+  nodes: []
+  path: .spec.template.spec.ephemeralContainers[*]
+kind: Service
+labels:
+  location: /spec/template/metadata/labels
+  pointer: /spec/template/metadata/labels
+  status: Resolved
+  value:
+    app: hello
+name: hello
+volumes:
+  location: /spec/template/spec/volumes
+  message: no value at "/spec/template/spec/volumes"
+  pointer: /spec/template/spec/volumes
+  status: Missing
+`,
+		},
+		{
+			name:        "invalid pod mapping",
+			args:        []string{"inspect", "--pod-mapping", invalidPodMappingFile},
+			expectedErr: "must be a JSON Pointer starting with '/'",
+		},
+		{
+			name:        "unknown output",
+			args:        []string{"inspect", "--output", "xml"},
+			expectedErr: `unknown output format "xml"`,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			err := run(c.args, strings.NewReader(c.stdin), stdout, stderr)
+
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Errorf("run() expected err containing %q, got %v", c.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, stdout.String()); diff != "" {
+				t.Errorf("run() stdout (-expected, +actual): %s", diff)
+			}
+			if diff := cmp.Diff(c.expectedStderr, stderr.String()); diff != "" {
+				t.Errorf("run() stderr (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
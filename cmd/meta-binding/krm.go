@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// functionConfig configures the KRM function.
+//
+//	apiVersion: meta-binding.scothis.github.io/v1alpha1
+//	kind: MetaBinding
+//	spec:
+//	  bindings: []
+//	  targets: []
+//	  mappings: []
+type functionConfig struct {
+	Spec bindConfig `json:"spec"`
+}
+
+// krmResult reports on an item of the ResourceList.
+type krmResult struct {
+	Message     string       `json:"message"`
+	Severity    string       `json:"severity"`
+	ResourceRef *resourceRef `json:"resourceRef,omitempty"`
+}
+
+type resourceRef struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// runKRM runs as a KRM function, reading a ResourceList from stdin and writing the updated
+// ResourceList to stdout. Items are bound in place, with their comments and the order of their
+// fields preserved. Items that failed or were skipped are reported in the results; the command
+// fails if any item failed.
+func runKRM(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("krm", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("malformed ResourceList: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("malformed ResourceList: expected an object")
+	}
+	resourceList := doc.Content[0]
+
+	config := &functionConfig{}
+	if fc := mappingValue(resourceList, "functionConfig"); fc != nil {
+		if err := decodeNode(fc, config); err != nil {
+			return fmt.Errorf("malformed functionConfig: %w", err)
+		}
+	}
+	opts, err := config.Spec.options()
+	if err != nil {
+		return fmt.Errorf("functionConfig: %w", err)
+	}
+	registry, err := config.Spec.registry()
+	if err != nil {
+		return fmt.Errorf("functionConfig: %w", err)
+	}
+
+	results := []krmResult{}
+	failed := 0
+	if items := mappingValue(resourceList, "items"); items != nil && items.Kind == yaml.SequenceNode {
+		for i, item := range items.Content {
+			value, err := nodeValue(item)
+			if err != nil {
+				results = append(results, krmResult{
+					Message:  fmt.Sprintf("item %d: %v", i, err),
+					Severity: "error",
+				})
+				failed++
+				continue
+			}
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			u := &unstructured.Unstructured{Object: obj}
+			selected, err := config.Spec.selects(u)
+			if err != nil {
+				return fmt.Errorf("functionConfig: %w", err)
+			}
+			if !selected {
+				continue
+			}
+			ref := &resourceRef{
+				APIVersion: u.GetAPIVersion(),
+				Kind:       u.GetKind(),
+				Name:       u.GetName(),
+				Namespace:  u.GetNamespace(),
+			}
+			m, ok := registry.Lookup(u.GroupVersionKind())
+			if !ok {
+				if len(config.Spec.Targets) != 0 {
+					results = append(results, krmResult{
+						Message:     fmt.Sprintf("skipped, no mapping for kind %s", u.GroupVersionKind().GroupKind()),
+						Severity:    "warning",
+						ResourceRef: ref,
+					})
+				}
+				continue
+			}
+			result, err := binding.BindAll(u, m, config.Spec.Bindings, opts)
+			if err != nil {
+				results = append(results, krmResult{
+					Message:     err.Error(),
+					Severity:    "error",
+					ResourceRef: ref,
+				})
+				failed++
+				continue
+			}
+			if reasons := skipped(result); len(reasons) != 0 {
+				results = append(results, krmResult{
+					Message:     fmt.Sprintf("skipped, no containers bound: %s", strings.Join(reasons, "; ")),
+					Severity:    "warning",
+					ResourceRef: ref,
+				})
+			}
+			if !result.Changed {
+				continue
+			}
+			bound, err := normalize(u.Object)
+			if err != nil {
+				return err
+			}
+			if items.Content[i], err = mergeNode(item, bound); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(results) != 0 {
+		value, err := normalize(results)
+		if err != nil {
+			return err
+		}
+		n, err := encodeNode(value)
+		if err != nil {
+			return err
+		}
+		setMappingValue(resourceList, "results", n)
+	}
+
+	out, err := encodeDocument(doc)
+	if err != nil {
+		return err
+	}
+	if _, err := stdout.Write(out); err != nil {
+		return err
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d items failed to bind", failed)
+	}
+	return nil
+}
+
+// skipped returns the reasons each container was skipped, or nil if any container was bound.
+func skipped(result binding.BindResult) []string {
+	reasons := []string{}
+	for _, c := range result.Containers {
+		if c.Status != binding.ContainerSkipped {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("container %q: %s", c.Name, c.Reason))
+	}
+	return reasons
+}
+
+// decodeNode decodes the YAML node into the target using its JSON field names.
+func decodeNode(n *yaml.Node, target interface{}) error {
+	value, err := nodeValue(n)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunKRM(t *testing.T) {
+	tests := []struct {
+		name        string
+		stdin       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "binds items preserving comments",
+			stdin: `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: hello # the app
+  spec:
+    replicas: 2
+    template:
+      metadata:
+        labels:
+          app: hello
+      spec:
+        containers:
+        - name: hello
+          image: hello:latest # pinned later
+          env:
+          - name: LOG_LEVEL
+            value: debug # noisy
+        - name: istio-proxy
+          image: proxy
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: config
+  data:
+    b: "2" # out of order
+    a: "1"
+functionConfig:
+  apiVersion: meta-binding.scothis.github.io/v1alpha1
+  kind: MetaBinding
+  spec:
+    bindings:
+    - name: db
+      secret:
+        name: db-secret
+`,
+			expected: `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: hello # the app
+  spec:
+    replicas: 2
+    template:
+      metadata:
+        labels:
+          app: hello
+        annotations:
+          meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}'
+      spec:
+        containers:
+        - name: hello
+          image: hello:latest # pinned later
+          env:
+          - name: LOG_LEVEL
+            value: debug # noisy
+          - name: SERVICE_BINDING_ROOT
+            value: /bindings
+          volumeMounts:
+          - mountPath: /bindings/db
+            name: binding-db
+            readOnly: true
+        - name: istio-proxy
+          image: proxy
+        volumes:
+        - name: binding-db
+          secret:
+            secretName: db-secret
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: config
+  data:
+    b: "2" # out of order
+    a: "1"
+functionConfig:
+  apiVersion: meta-binding.scothis.github.io/v1alpha1
+  kind: MetaBinding
+  spec:
+    bindings:
+    - name: db
+      secret:
+        name: db-secret
+`,
+		},
+		{
+			name: "targets and mappings",
+			stdin: `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: hello
+    labels:
+      tier: web
+  spec:
+    template:
+      spec:
+        containers:
+        - name: hello
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: cache
+    labels:
+      tier: cache
+  spec:
+    template:
+      spec:
+        containers:
+        - name: cache
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata:
+    name: widget
+  spec:
+    workers:
+    - name: worker
+- apiVersion: example.com/v1
+  kind: Gadget
+  metadata:
+    name: gadget
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: sidecar
+  spec:
+    template:
+      spec:
+        containers:
+        - name: linkerd-proxy
+        volumes:
+        - name: binding-db
+          secret:
+            secretName: db-secret
+      metadata:
+        annotations:
+          meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db"}}'
+functionConfig:
+  apiVersion: meta-binding.scothis.github.io/v1alpha1
+  kind: MetaBinding
+  spec:
+    bindings:
+    - name: db
+      secret:
+        name: db-secret
+    targets:
+    - kind: Deployment
+      labels: tier=web
+    - group: example.com
+    - name: sidecar
+    mappings:
+    - group: example.com
+      kind: Widget
+      mapping:
+        annotations: /metadata/annotations
+        labels: /metadata/labels
+        containers:
+        - path: .spec.workers[*]
+          name: /name
+        volumes: /spec/volumes
+`,
+			expected: `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: hello
+    labels:
+      tier: web
+  spec:
+    template:
+      spec:
+        containers:
+        - name: hello
+          env:
+          - name: SERVICE_BINDING_ROOT
+            value: /bindings
+          volumeMounts:
+          - mountPath: /bindings/db
+            name: binding-db
+            readOnly: true
+        volumes:
+        - name: binding-db
+          secret:
+            secretName: db-secret
+      metadata:
+        annotations:
+          meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}'
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: cache
+    labels:
+      tier: cache
+  spec:
+    template:
+      spec:
+        containers:
+        - name: cache
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata:
+    name: widget
+    annotations:
+      meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"worker":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"worker":["/bindings/db"]}}}'
+  spec:
+    workers:
+    - name: worker
+      env:
+      - name: SERVICE_BINDING_ROOT
+        value: /bindings
+      volumeMounts:
+      - mountPath: /bindings/db
+        name: binding-db
+        readOnly: true
+    volumes:
+    - name: binding-db
+      secret:
+        secretName: db-secret
+- apiVersion: example.com/v1
+  kind: Gadget
+  metadata:
+    name: gadget
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: sidecar
+  spec:
+    template:
+      spec:
+        containers:
+        - name: linkerd-proxy
+        volumes:
+        - name: binding-db
+          secret:
+            secretName: db-secret
+      metadata:
+        annotations:
+          meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db"}}'
+functionConfig:
+  apiVersion: meta-binding.scothis.github.io/v1alpha1
+  kind: MetaBinding
+  spec:
+    bindings:
+    - name: db
+      secret:
+        name: db-secret
+    targets:
+    - kind: Deployment
+      labels: tier=web
+    - group: example.com
+    - name: sidecar
+    mappings:
+    - group: example.com
+      kind: Widget
+      mapping:
+        annotations: /metadata/annotations
+        labels: /metadata/labels
+        containers:
+        - path: .spec.workers[*]
+          name: /name
+        volumes: /spec/volumes
+results:
+- message: skipped, no mapping for kind Gadget.example.com
+  resourceRef:
+    apiVersion: example.com/v1
+    kind: Gadget
+    name: gadget
+  severity: warning
+- message: 'skipped, no containers bound: container "linkerd-proxy": binding "db": container not selected'
+  resourceRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: sidecar
+  severity: warning
+`,
+		},
+		{
+			name: "failed items",
+			stdin: `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: hello
+  spec:
+    containers:
+    - name: hello
+    volumes:
+    - name: binding-db
+functionConfig:
+  spec:
+    bindings:
+    - name: db
+      secret:
+        name: db-secret
+`,
+			expected: `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: hello
+  spec:
+    containers:
+    - name: hello
+    volumes:
+    - name: binding-db
+functionConfig:
+  spec:
+    bindings:
+    - name: db
+      secret:
+        name: db-secret
+results:
+- message: 'binding "db": binding "db": volume "binding-db" owned by user: volume already exists'
+  resourceRef:
+    apiVersion: v1
+    kind: Pod
+    name: hello
+  severity: error
+`,
+			expectedErr: "1 items failed to bind",
+		},
+		{
+			name: "invalid functionConfig",
+			stdin: `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items: []
+functionConfig:
+  spec:
+    conflictPolicy: Ignore
+`,
+			expectedErr: `functionConfig: unknown conflict policy "Ignore"`,
+		},
+		{
+			name:        "malformed ResourceList",
+			stdin:       `[]`,
+			expectedErr: "malformed ResourceList",
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			err := run([]string{"krm"}, strings.NewReader(c.stdin), stdout, stderr)
+
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Errorf("run() expected err containing %q, got %v", c.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("run() unexpected err: %v", err)
+			}
+			if c.expected == "" {
+				return
+			}
+			if diff := cmp.Diff(c.expected, stdout.String()); diff != "" {
+				t.Errorf("run() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Command meta-binding applies service bindings to Kubernetes manifests.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: meta-binding <command> [flags] [files...]
+
+commands:
+  bind         bind the workloads within manifests read from files, or stdin
+  inspect      print the MetaPodTemplate of each workload within manifests read from files, or stdin
+  explain      print how the mapping of each workload resolves, to debug custom mappings
+  krm          run as a KRM function, binding the items of a ResourceList read from stdin
+  post-render  run as a Helm post-renderer, binding the rendered manifests read from stdin
+  webhook      serve a mutating admission webhook, binding workloads as they are admitted
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "meta-binding: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("missing command")
+	}
+	switch args[0] {
+	case "bind":
+		return runBind(args[1:], stdin, stdout, stderr)
+	case "inspect":
+		return runInspect(args[1:], stdin, stdout, stderr)
+	case "explain":
+		return runExplain(args[1:], stdin, stdout, stderr)
+	case "krm":
+		return runKRM(args[1:], stdin, stdout, stderr)
+	case "post-render":
+		return runPostRender(args[1:], stdin, stdout, stderr)
+	case "webhook":
+		return runWebhook(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
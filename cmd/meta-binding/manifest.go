@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// document is a single document within a stream of manifests. Documents that are not modified
+// are written back exactly as they were read.
+type document struct {
+	raw []byte
+	// object is the decoded document, or nil if the document is not an object.
+	object *unstructured.Unstructured
+	// json is true if the document was encoded as JSON rather than YAML.
+	json bool
+	// modified is true if the object must be encoded in place of the raw document.
+	modified bool
+}
+
+// readManifests reads the documents from each of the files in order. The file "-" and an empty
+// list of files read from stdin.
+func readManifests(files []string, stdin io.Reader) ([]*document, error) {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	docs := []*document{}
+	for _, file := range files {
+		var r io.Reader = stdin
+		if file != "-" {
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		d, err := readDocuments(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		docs = append(docs, d...)
+	}
+	return docs, nil
+}
+
+// readDocuments splits a stream of YAML or JSON documents. Empty documents are dropped, while
+// documents that are not objects, such as comments, are retained as is.
+func readDocuments(r io.Reader) ([]*document, error) {
+	docs := []*document{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		raw, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		trimmed := bytes.TrimSpace(raw)
+		if len(trimmed) == 0 {
+			continue
+		}
+		d := &document{raw: raw}
+		docs = append(docs, d)
+
+		data, err := sigsyaml.YAMLToJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", len(docs), err)
+		}
+		obj := map[string]interface{}{}
+		// integers are decoded as int64 rather than float64
+		if err := utiljson.Unmarshal(data, &obj); err != nil || len(obj) == 0 {
+			continue
+		}
+		d.object = &unstructured.Unstructured{Object: obj}
+		d.json = trimmed[0] == '{'
+	}
+	return docs, nil
+}
+
+// writeDocuments writes the documents in order, separated as a YAML stream.
+func writeDocuments(w io.Writer, docs []*document) error {
+	for i, d := range docs {
+		if i != 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		data := d.raw
+		if d.modified {
+			var err error
+			if d.json {
+				data, err = json.MarshalIndent(d.object.Object, "", "  ")
+			} else {
+				data, err = mergeDocument(d.raw, d.object.Object)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(data) != 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeDocument updates the YAML document to hold the object, preserving comments and the order
+// of fields that are not changed.
+func mergeDocument(raw []byte, obj map[string]interface{}) ([]byte, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(raw, doc); err != nil {
+		return nil, err
+	}
+	value, err := normalize(obj)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Content[0], err = mergeNode(doc.Content[0], value); err != nil {
+		return nil, err
+	}
+	return encodeDocument(doc)
+}
+
+// encodeDocument encodes the YAML document node with two space indentation. Sequences within
+// mappings are written in the compact style of kubectl, with the `-` indicator aligned to the
+// mapping key.
+func encodeDocument(doc *yaml.Node) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return compactSequences(buf.Bytes())
+}
+
+// compactSequences outdents the block sequences yaml.v3 indents beneath a mapping key. The
+// encoded document is parsed to find the sequences, so the content of block scalars is never
+// mistaken for a sequence. Every line of a sequence is outdented by the same amount, keeping
+// nested collections and block scalars intact.
+func compactSequences(data []byte) ([]byte, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	// columns of the `-` indicator of the sequences starting on each line
+	starts := map[int][]int{}
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.MappingNode {
+			for i := 1; i < len(n.Content); i += 2 {
+				v := n.Content[i]
+				if v.Kind == yaml.SequenceNode && v.Style&yaml.FlowStyle == 0 && len(v.Content) != 0 {
+					starts[v.Line] = append(starts[v.Line], v.Column-1)
+				}
+			}
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	// comments ahead of the first item belong to the sequence
+	sequences := map[int][]int{}
+	for line, columns := range starts {
+		comment := append(bytes.Repeat([]byte(" "), columns[0]), '#')
+		for line > 1 && bytes.HasPrefix(lines[line-2], comment) {
+			line--
+		}
+		sequences[line] = append(sequences[line], columns...)
+	}
+	out := make([]byte, 0, len(data))
+	// columns of the enclosing sequences, innermost last
+	open := []int{}
+	for i, line := range lines {
+		indent := len(line) - len(bytes.TrimLeft(line, " "))
+		blank := len(bytes.TrimSpace(line)) == 0
+		for !blank && len(open) != 0 && indent < open[len(open)-1] {
+			open = open[:len(open)-1]
+		}
+		open = append(open, sequences[i+1]...)
+		shift := 2 * len(open)
+		if shift > indent {
+			shift = indent
+		}
+		out = append(out, line[shift:]...)
+	}
+	return out, nil
+}
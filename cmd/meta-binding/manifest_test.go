@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompactSequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "nested sequences",
+			input: `spec:
+  containers:
+    - name: web
+      env:
+        - name: A
+          value: a
+  volumes:
+    - name: data
+`,
+			expected: `spec:
+  containers:
+  - name: web
+    env:
+    - name: A
+      value: a
+  volumes:
+  - name: data
+`,
+		},
+		{
+			name: "sequence of sequences",
+			input: `matrix:
+  - - a
+    - b
+  - - c
+`,
+			expected: `matrix:
+- - a
+  - b
+- - c
+`,
+		},
+		{
+			name: "block scalars are outdented with their sequence",
+			input: `data:
+  - name: config
+    value: |
+      items:
+        - not a sequence
+      done: true
+config.yaml: |
+  items:
+    - not a sequence
+`,
+			expected: `data:
+- name: config
+  value: |
+    items:
+      - not a sequence
+    done: true
+config.yaml: |
+  items:
+    - not a sequence
+`,
+		},
+		{
+			name: "comments",
+			input: `items:
+  # first
+  - a
+  - b
+# after
+other: c
+`,
+			expected: `items:
+# first
+- a
+- b
+# after
+other: c
+`,
+		},
+		{
+			name: "flow sequences are untouched",
+			input: `args: [a, b]
+`,
+			expected: `args: [a, b]
+`,
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := compactSequences([]byte(c.input))
+			if err != nil {
+				t.Fatalf("compactSequences() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, string(actual)); diff != "" {
+				t.Errorf("compactSequences() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
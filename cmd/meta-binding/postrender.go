@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"sigs.k8s.io/yaml"
+)
+
+// runPostRender runs as a Helm post-renderer, binding the workloads selected by the config file
+// within the rendered manifests read from stdin. Every document is written to stdout in the
+// order it was read; documents that are not bound are written untouched.
+func runPostRender(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("post-render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFile := fs.String("config", "", "`file` holding the bindings, targets and mappings to apply")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *configFile == "" {
+		return fmt.Errorf("--config is required")
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments %q, manifests are read from stdin", fs.Args())
+	}
+
+	config, err := loadBindConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	opts, err := config.options()
+	if err != nil {
+		return fmt.Errorf("%s: %w", *configFile, err)
+	}
+	registry, err := config.registry()
+	if err != nil {
+		return fmt.Errorf("%s: %w", *configFile, err)
+	}
+
+	docs, err := readDocuments(stdin)
+	if err != nil {
+		return err
+	}
+	if err := bindDocuments(docs, registry, config.Bindings, opts, config.selects); err != nil {
+		return err
+	}
+	return writeDocuments(stdout, docs)
+}
+
+// loadBindConfig reads the bindings, targets and mappings to apply from a YAML or JSON file.
+func loadBindConfig(file string) (*bindConfig, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	config := &bindConfig{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(config.Bindings) == 0 {
+		return nil, fmt.Errorf("%s: at least one binding is required", file)
+	}
+	return config, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunPostRender(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(`
+bindings:
+- name: db
+  secret:
+    name: db-secret
+targets:
+- kind: Deployment
+  labels: app.kubernetes.io/component=web
+- kind: StatefulSet
+  name: cache
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	emptyConfigFile := filepath.Join(dir, "empty.yaml")
+	if err := ioutil.WriteFile(emptyConfigFile, []byte(`targets: []`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		stdin       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "binds selected workloads",
+			args: []string{"post-render", "--config", configFile},
+			stdin: `---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: chart/templates/web.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/component: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0.0
+---
+# Source: chart/templates/worker.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  labels:
+    app.kubernetes.io/component: worker
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: worker:1.0.0
+---
+# Source: chart/templates/cache.yaml
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: cache
+spec:
+  template:
+    spec:
+      containers:
+      - name: cache
+        image: cache:1.0.0
+`,
+			expected: `---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: chart/templates/web.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/component: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0.0
+        env:
+        - name: SERVICE_BINDING_ROOT
+          value: /bindings
+        volumeMounts:
+        - mountPath: /bindings/db
+          name: binding-db
+          readOnly: true
+      volumes:
+      - name: binding-db
+        secret:
+          secretName: db-secret
+    metadata:
+      annotations:
+        meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"web":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"web":["/bindings/db"]}}}'
+---
+# Source: chart/templates/worker.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  labels:
+    app.kubernetes.io/component: worker
+spec:
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: worker:1.0.0
+---
+# Source: chart/templates/cache.yaml
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: cache
+spec:
+  template:
+    spec:
+      containers:
+      - name: cache
+        image: cache:1.0.0
+        env:
+        - name: SERVICE_BINDING_ROOT
+          value: /bindings
+        volumeMounts:
+        - mountPath: /bindings/db
+          name: binding-db
+          readOnly: true
+      volumes:
+      - name: binding-db
+        secret:
+          secretName: db-secret
+    metadata:
+      annotations:
+        meta-binding.scothis.github.io/bindings: '{"db":{"volume":"binding-db","env":{"cache":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"cache":["/bindings/db"]}}}'
+`,
+		},
+		{
+			name:        "missing config",
+			args:        []string{"post-render"},
+			expectedErr: "--config is required",
+		},
+		{
+			name:        "config without bindings",
+			args:        []string{"post-render", "--config", emptyConfigFile},
+			expectedErr: "at least one binding is required",
+		},
+		{
+			name:        "manifest arguments",
+			args:        []string{"post-render", "--config", configFile, "manifest.yaml"},
+			expectedErr: "manifests are read from stdin",
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			err := run(c.args, strings.NewReader(c.stdin), stdout, stderr)
+
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Errorf("run() expected err containing %q, got %v", c.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, stdout.String()); diff != "" {
+				t.Errorf("run() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	"github.com/scothis/unstructured-meta-binding/webhook"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// runWebhook serves a mutating admission webhook over TLS, binding the workloads selected by the
+// config file as they are created or updated.
+func runWebhook(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("webhook", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFile := fs.String("config", "", "`file` holding the bindings, targets and mappings to apply")
+	addr := fs.String("addr", ":8443", "`address` to listen on")
+	certFile := fs.String("tls-cert-file", "", "`file` holding the serving certificate")
+	keyFile := fs.String("tls-key-file", "", "`file` holding the serving certificate's private key")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *configFile == "" {
+		return fmt.Errorf("--config is required")
+	}
+	if *certFile == "" || *keyFile == "" {
+		return fmt.Errorf("--tls-cert-file and --tls-key-file are required, the API server only calls webhooks over TLS")
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	config, err := loadBindConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	handler, err := newWebhookHandler(config)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *configFile, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/bind", handler)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{
+		Addr:         *addr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	fmt.Fprintf(stderr, "serving admission webhook at https://%s/bind\n", *addr)
+	return server.ListenAndServeTLS(*certFile, *keyFile)
+}
+
+// newWebhookHandler returns a handler applying the config's bindings to the workloads selected
+// by its targets.
+func newWebhookHandler(config *bindConfig) (*webhook.Handler, error) {
+	opts, err := config.options()
+	if err != nil {
+		return nil, err
+	}
+	registry, err := config.registry()
+	if err != nil {
+		return nil, err
+	}
+	return &webhook.Handler{
+		Registry: registry,
+		Bindings: webhook.BindingLookupFunc(func(ctx context.Context, req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) ([]binding.Binding, error) {
+			// objects being created may not have a namespace yet
+			if obj.GetNamespace() == "" && req.Namespace != "" {
+				obj = obj.DeepCopy()
+				obj.SetNamespace(req.Namespace)
+			}
+			selected, err := config.selects(obj)
+			if err != nil || !selected {
+				return nil, err
+			}
+			return config.Bindings, nil
+		}),
+		Options: opts,
+	}, nil
+}
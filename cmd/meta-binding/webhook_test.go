@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	binding "github.com/scothis/unstructured-meta-binding"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	config := &bindConfig{
+		Bindings: []binding.Binding{
+			{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+		},
+		Targets: []selector{
+			{
+				Kind:      "Deployment",
+				Namespace: "shop",
+			},
+		},
+	}
+	handler, err := newWebhookHandler(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// the object of a create request does not have a namespace yet
+	review := `{
+  "apiVersion": "admission.k8s.io/v1",
+  "kind": "AdmissionReview",
+  "request": {
+    "uid": "e911857d-c318-11e8-bbad-025000000001",
+    "kind": {"group": "apps", "version": "v1", "kind": "Deployment"},
+    "resource": {"group": "apps", "version": "v1", "resource": "deployments"},
+    "namespace": %q,
+    "operation": "CREATE",
+    "userInfo": {"username": "admin"},
+    "object": {
+      "apiVersion": "apps/v1",
+      "kind": "Deployment",
+      "metadata": {"generateName": "web-"},
+      "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "web"}]}}}
+    }
+  }
+}`
+
+	tests := []struct {
+		name          string
+		namespace     string
+		expectedPatch bool
+	}{
+		{
+			name:          "targeted namespace",
+			namespace:     "shop",
+			expectedPatch: true,
+		},
+		{
+			name:          "other namespace",
+			namespace:     "default",
+			expectedPatch: false,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL, "application/json", bytes.NewReader([]byte(fmt.Sprintf(review, c.namespace))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("ServeHTTP() unexpected status %d", resp.StatusCode)
+			}
+			actual := &admissionv1.AdmissionReview{}
+			if err := json.NewDecoder(resp.Body).Decode(actual); err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Response.Allowed {
+				t.Errorf("ServeHTTP() expected allowed, got %v", actual.Response.Result)
+			}
+			if diff := cmp.Diff(c.expectedPatch, actual.Response.Patch != nil); diff != "" {
+				t.Errorf("ServeHTTP() patched (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{
+			name:        "missing config",
+			args:        []string{"webhook"},
+			expectedErr: "--config is required",
+		},
+		{
+			name:        "missing certificate",
+			args:        []string{"webhook", "--config", "config.yaml", "--tls-key-file", "tls.key"},
+			expectedErr: "--tls-cert-file and --tls-key-file are required",
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			err := run(c.args, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+				t.Errorf("run() expected err containing %q, got %v", c.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// nodeValue decodes the YAML node into an unstructured value.
+func nodeValue(n *yaml.Node) (interface{}, error) {
+	var v interface{}
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	return normalize(v)
+}
+
+// normalize converts the value to the types used by unstructured objects, integers are int64
+// and floats are float64.
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := utiljson.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// mergeNode updates the YAML node to hold the normalized value, changing only what differs.
+// Entries of mappings keep their order and comments, new entries are appended in sorted order.
+// Items of sequences are matched by value, then by their `name` or `mountPath`, so unchanged
+// items keep their comments when other items are inserted or removed. New empty mappings and
+// sequences are omitted.
+func mergeNode(n *yaml.Node, value interface{}) (*yaml.Node, error) {
+	if current, err := nodeValue(n); err == nil && equality.Semantic.DeepEqual(current, value) {
+		return n, nil
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if n.Kind != yaml.MappingNode {
+			return replaceNode(n, value)
+		}
+		content := []*yaml.Node{}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			child, ok := v[key]
+			if !ok {
+				continue
+			}
+			seen[key] = true
+			merged, err := mergeNode(n.Content[i+1], child)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, n.Content[i], merged)
+		}
+		keys := []string{}
+		for key := range v {
+			if !seen[key] && !isEmpty(v[key]) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, err := encodeNode(pruneEmpty(v[key]))
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		setContent(n, content)
+		return n, nil
+	case []interface{}:
+		if n.Kind != yaml.SequenceNode {
+			return replaceNode(n, value)
+		}
+		content := []*yaml.Node{}
+		used := make([]bool, len(n.Content))
+		for _, item := range v {
+			j := matchItem(n.Content, used, item)
+			if j < 0 {
+				child, err := encodeNode(pruneEmpty(item))
+				if err != nil {
+					return nil, err
+				}
+				content = append(content, child)
+				continue
+			}
+			used[j] = true
+			merged, err := mergeNode(n.Content[j], item)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, merged)
+		}
+		setContent(n, content)
+		return n, nil
+	default:
+		return replaceNode(n, value)
+	}
+}
+
+// setContent replaces the content of a mapping or sequence node. Empty nodes written in flow
+// style, like `{}`, are switched to block style once they have content.
+func setContent(n *yaml.Node, content []*yaml.Node) {
+	if len(n.Content) == 0 && len(content) != 0 {
+		n.Style &^= yaml.FlowStyle
+	}
+	n.Content = content
+}
+
+// matchItem returns the index of the unused item equal to the value, or failing that of the
+// unused item with the same identity, or -1 if there is no match.
+func matchItem(items []*yaml.Node, used []bool, value interface{}) int {
+	for j, item := range items {
+		if used[j] {
+			continue
+		}
+		if current, err := nodeValue(item); err == nil && equality.Semantic.DeepEqual(current, value) {
+			return j
+		}
+	}
+	id := identity(value)
+	if id == "" {
+		return -1
+	}
+	for j, item := range items {
+		if used[j] {
+			continue
+		}
+		if current, err := nodeValue(item); err == nil && identity(current) == id {
+			return j
+		}
+	}
+	return -1
+}
+
+// identity returns the key identifying an item within a list, such as the name of a container,
+// environment variable or volume, or the path of a volume mount.
+func identity(value interface{}) string {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if name, ok := obj["name"].(string); ok {
+		return "name=" + name
+	}
+	if mountPath, ok := obj["mountPath"].(string); ok {
+		return "mountPath=" + mountPath
+	}
+	return ""
+}
+
+func encodeNode(value interface{}) (*yaml.Node, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc.Content[0], nil
+}
+
+// replaceNode encodes the value in place of the node, retaining the node's comments.
+func replaceNode(n *yaml.Node, value interface{}) (*yaml.Node, error) {
+	replacement, err := encodeNode(value)
+	if err != nil {
+		return nil, err
+	}
+	replacement.HeadComment = n.HeadComment
+	replacement.LineComment = n.LineComment
+	replacement.FootComment = n.FootComment
+	return replacement, nil
+}
+
+// pruneEmpty returns the value without the empty mappings and sequences held by its mappings.
+func pruneEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		pruned := map[string]interface{}{}
+		for key, child := range v {
+			if child = pruneEmpty(child); !isEmpty(child) {
+				pruned[key] = child
+			}
+		}
+		return pruned
+	case []interface{}:
+		pruned := make([]interface{}, len(v))
+		for i := range v {
+			pruned[i] = pruneEmpty(v[i])
+		}
+		return pruned
+	}
+	return value
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// mappingValue returns the value node of the key within the mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value node of the key within the mapping node, appending the key if
+// it is not already present.
+func setMappingValue(n *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content[i+1] = value
+			return
+		}
+	}
+	n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
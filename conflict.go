@@ -0,0 +1,79 @@
+package binding
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ConflictPolicy determines how a binding treats a volume or volume mount it does not own that
+// collides with an entry it would create.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyFail returns a ConflictError for the collision. This is the default.
+	ConflictPolicyFail ConflictPolicy = "Fail"
+	// ConflictPolicySkip leaves the existing entry in place. A colliding volume skips mounting
+	// the binding into every container, while a colliding volume mount skips the container.
+	ConflictPolicySkip ConflictPolicy = "Skip"
+	// ConflictPolicyTakeOver replaces an existing entry with the same name or path, and records
+	// it as owned by the binding. Mounts that shadow each other cannot be taken over and fail.
+	ConflictPolicyTakeOver ConflictPolicy = "TakeOver"
+)
+
+// ConflictError is returned when a binding collides with an existing volume or volume mount.
+type ConflictError struct {
+	// Binding is the name of the binding that could not be applied.
+	Binding string
+	// Container is the name of the container holding the colliding volume mount. Empty for
+	// volume collisions.
+	Container string
+	// Existing is the name of the colliding volume, or the path of the colliding volume mount.
+	Existing string
+	// Owner is the name of another binding owning the existing entry. Empty when the entry is
+	// owned by the user.
+	Owner string
+	// Reason describes the collision.
+	Reason string
+}
+
+func (e *ConflictError) Error() string {
+	owner := "user"
+	if e.Owner != "" {
+		owner = fmt.Sprintf("binding %q", e.Owner)
+	}
+	if e.Container == "" {
+		return fmt.Sprintf("binding %q: volume %q owned by %s: %s", e.Binding, e.Existing, owner, e.Reason)
+	}
+	return fmt.Sprintf("binding %q: container %q: volume mount at %q owned by %s: %s", e.Binding, e.Container, e.Existing, owner, e.Reason)
+}
+
+// mountConflict describes how the existing volume mount collides with the mount, or returns an
+// empty string if they do not collide. Mounts that share a volume name or path may be taken
+// over, while nested mounts shadow each other and may not.
+func mountConflict(existing, mount corev1.VolumeMount) (reason string, nested bool) {
+	existingPath := path.Clean(existing.MountPath)
+	mountPath := path.Clean(mount.MountPath)
+	switch {
+	case existing.Name == mount.Name:
+		return fmt.Sprintf("already mounts volume %q", mount.Name), false
+	case existingPath == mountPath:
+		return "same mount path", false
+	case isWithin(mountPath, existingPath):
+		return fmt.Sprintf("shadows %q", mountPath), true
+	case isWithin(existingPath, mountPath):
+		return fmt.Sprintf("shadowed by %q", mountPath), true
+	}
+	return "", false
+}
+
+// isWithin returns true if the child path is nested below the parent path. Both paths must be
+// clean.
+func isWithin(parent, child string) bool {
+	if parent == "/" {
+		return child != "/" && strings.HasPrefix(child, "/")
+	}
+	return strings.HasPrefix(child, parent+"/")
+}
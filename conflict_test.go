@@ -0,0 +1,129 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMountConflict(t *testing.T) {
+	mount := corev1.VolumeMount{
+		Name:      "binding-db",
+		MountPath: "/bindings/db",
+	}
+
+	tests := []struct {
+		name           string
+		existing       corev1.VolumeMount
+		expectedReason string
+		expectedNested bool
+	}{
+		{
+			name: "unrelated",
+			existing: corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/config",
+			},
+		},
+		{
+			name: "sibling with common prefix",
+			existing: corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/bindings/db-replica",
+			},
+		},
+		{
+			name: "same volume",
+			existing: corev1.VolumeMount{
+				Name:      "binding-db",
+				MountPath: "/other",
+			},
+			expectedReason: `already mounts volume "binding-db"`,
+		},
+		{
+			name: "same path",
+			existing: corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/bindings/db/",
+			},
+			expectedReason: "same mount path",
+		},
+		{
+			name: "parent path",
+			existing: corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/bindings",
+			},
+			expectedReason: `shadowed by "/bindings/db"`,
+			expectedNested: true,
+		},
+		{
+			name: "root path",
+			existing: corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/",
+			},
+			expectedReason: `shadowed by "/bindings/db"`,
+			expectedNested: true,
+		},
+		{
+			name: "child path",
+			existing: corev1.VolumeMount{
+				Name:      "config",
+				MountPath: "/bindings/db/config",
+			},
+			expectedReason: `shadows "/bindings/db"`,
+			expectedNested: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			reason, nested := mountConflict(c.existing, mount)
+			if diff := cmp.Diff(c.expectedReason, reason); diff != "" {
+				t.Errorf("mountConflict() reason (-expected, +actual): %s", diff)
+			}
+			if c.expectedNested != nested {
+				t.Errorf("mountConflict() expected nested %v, got %v", c.expectedNested, nested)
+			}
+		})
+	}
+}
+
+func TestConflictError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *ConflictError
+		expected string
+	}{
+		{
+			name: "user volume",
+			err: &ConflictError{
+				Binding:  "db",
+				Existing: "binding-db",
+				Reason:   "volume already exists",
+			},
+			expected: `binding "db": volume "binding-db" owned by user: volume already exists`,
+		},
+		{
+			name: "binding volume mount",
+			err: &ConflictError{
+				Binding:   "db/replica",
+				Container: "hello",
+				Existing:  "/bindings/db",
+				Owner:     "db",
+				Reason:    `shadowed by "/bindings/db/replica"`,
+			},
+			expected: `binding "db/replica": container "hello": volume mount at "/bindings/db" owned by binding "db": shadowed by "/bindings/db/replica"`,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := cmp.Diff(c.expected, c.err.Error()); diff != "" {
+				t.Errorf("Error() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
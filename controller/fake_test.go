@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newFakeClient returns client-go's fake dynamic client holding the objects. Every resource used
+// by the tests is registered with its list kind so that it may be listed and watched.
+func newFakeClient(resources map[schema.GroupVersionResource][]*unstructured.Unstructured) *dynamicfake.FakeDynamicClient {
+	objects := []runtime.Object{}
+	for _, objs := range resources {
+		for _, obj := range objs {
+			objects = append(objects, obj.DeepCopy())
+		}
+	}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		ServiceBindingResource: "ServiceBindingList",
+		deploymentsResource:    "DeploymentList",
+		statefulSetsResource:   "StatefulSetList",
+		databasesResource:      "DatabaseList",
+		widgetsResource:        "WidgetList",
+	}, objects...)
+}
+
+// conflictOnUpdate fails the next updates of the resource, including status updates, as if
+// the object was modified concurrently. The remaining count is decremented with each failure.
+func conflictOnUpdate(client *dynamicfake.FakeDynamicClient, gvr schema.GroupVersionResource, remaining *int) {
+	client.PrependReactor("update", gvr.Resource, func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj, err := meta.Accessor(action.(clienttesting.UpdateAction).GetObject())
+		if err != nil || *remaining == 0 {
+			return false, nil, nil
+		}
+		*remaining--
+		return true, nil, apierrors.NewConflict(gvr.GroupResource(), obj.GetName(), fmt.Errorf("the object has been modified"))
+	})
+}
+
+// getObject returns the object held by the client, or nil if it does not exist.
+func getObject(client *dynamicfake.FakeDynamicClient, gvr schema.GroupVersionResource, namespace, name string) *unstructured.Unstructured {
+	obj, err := client.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return obj
+}
+
+// writes describes each update made with the client, such as `update deployments web` or
+// `update-status servicebindings db`. Updates that failed are included.
+func writes(client *dynamicfake.FakeDynamicClient) []string {
+	out := []string{}
+	for _, action := range client.Actions() {
+		update, ok := action.(clienttesting.UpdateAction)
+		if !ok || action.GetVerb() != "update" {
+			continue
+		}
+		obj, err := meta.Accessor(update.GetObject())
+		if err != nil {
+			continue
+		}
+		verb := action.GetVerb()
+		if action.GetSubresource() != "" {
+			verb = fmt.Sprintf("%s-%s", verb, action.GetSubresource())
+		}
+		out = append(out, fmt.Sprintf("%s %s %s", verb, action.GetResource().Resource, obj.GetName()))
+	}
+	return out
+}
@@ -0,0 +1,559 @@
+// Package controller applies ServiceBindings to the workloads of a live cluster.
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	"github.com/scothis/unstructured-meta-binding/apis/servicebinding/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// ServiceBindingResource is the resource of ServiceBindings.
+var ServiceBindingResource = v1alpha3.SchemeGroupVersion.WithResource("servicebindings")
+
+// Reconciler applies ServiceBindings to the workloads they reference. Services and workloads are
+// read and written with the dynamic client, so any kind of workload with a mapping in the
+// Registry may be bound.
+type Reconciler struct {
+	// Client reads and writes ServiceBindings, services and workloads.
+	Client dynamic.Interface
+	// Mapper resolves the resource of the service and workload kinds.
+	Mapper meta.RESTMapper
+	// Registry holds the mappings of the workload kinds that may be bound.
+	Registry binding.Registry
+	// Options used to bind each workload. Options.SecretGetter is ignored, as a getter reads the
+	// secrets of a single namespace; use SecretGetter instead.
+	// +optional
+	Options binding.BindOptions
+	// SecretGetter, when set, returns the getter used to check the secrets of the ServiceBindings
+	// in a namespace, such as `secretLister.Secrets`.
+	// +optional
+	SecretGetter func(namespace string) binding.SecretGetter
+	// Backoff between attempts to update an object that was modified concurrently. Defaults to
+	// retry.DefaultRetry.
+	// +optional
+	Backoff *wait.Backoff
+}
+
+// Run reconciles every ServiceBinding in the namespace, and then each ServiceBinding as it
+// changes, until the context is done. An empty namespace watches every namespace. The watch is
+// restarted, and every ServiceBinding reconciled again, after the resync period so that changes
+// to services and workloads are eventually applied. Errors reconciling a ServiceBinding are
+// reported to utilruntime.HandleError and retried with the next resync.
+func (r *Reconciler) Run(ctx context.Context, namespace string, resync time.Duration) error {
+	client := r.Client.Resource(ServiceBindingResource).Namespace(namespace)
+	for {
+		list, err := client.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			r.handle(ctx, &list.Items[i])
+		}
+
+		timeout := int64(resync / time.Second)
+		w, err := client.Watch(ctx, metav1.ListOptions{
+			ResourceVersion: list.GetResourceVersion(),
+			TimeoutSeconds:  &timeout,
+		})
+		if err != nil {
+			return err
+		}
+		r.watch(ctx, w)
+		w.Stop()
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// watch reconciles each ServiceBinding added or modified, until the watch ends or the context
+// is done.
+func (r *Reconciler) watch(ctx context.Context, w watch.Interface) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if u, ok := event.Object.(*unstructured.Unstructured); ok {
+					r.handle(ctx, u)
+				}
+			case watch.Error:
+				utilruntime.HandleError(apierrors.FromObject(event.Object))
+				return
+			}
+		}
+	}
+}
+
+func (r *Reconciler) handle(ctx context.Context, u *unstructured.Unstructured) {
+	if err := r.Reconcile(ctx, u.GetNamespace(), u.GetName()); err != nil {
+		utilruntime.HandleError(fmt.Errorf("reconciling ServiceBinding %s/%s: %w", u.GetNamespace(), u.GetName(), err))
+	}
+}
+
+// Reconcile applies the named ServiceBinding to its workloads and updates its status. A
+// ServiceBinding being deleted is removed from its workloads before its finalizer is released.
+// Problems with the ServiceBinding, its service or workloads are reported in the status
+// conditions, while an error is returned when the reconciliation should be retried.
+func (r *Reconciler) Reconcile(ctx context.Context, namespace, name string) error {
+	client := r.Client.Resource(ServiceBindingResource).Namespace(namespace)
+	u, err := client.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sb, err := toServiceBinding(u)
+	if err != nil {
+		return err
+	}
+
+	if sb.DeletionTimestamp != nil {
+		if !hasFinalizer(sb) {
+			return nil
+		}
+		if err := r.unbind(ctx, sb); err != nil {
+			return err
+		}
+		return r.updateServiceBinding(ctx, sb, func(latest *v1alpha3.ServiceBinding) {
+			latest.Finalizers = removeString(latest.Finalizers, v1alpha3.ServiceBindingFinalizer)
+		})
+	}
+	if !hasFinalizer(sb) {
+		// the finalizer must be in place before the workloads are modified, otherwise a deleted
+		// ServiceBinding could not be removed from them
+		if err := r.updateServiceBinding(ctx, sb, func(latest *v1alpha3.ServiceBinding) {
+			latest.Finalizers = append(latest.Finalizers, v1alpha3.ServiceBindingFinalizer)
+		}); err != nil {
+			return err
+		}
+	}
+
+	status := sb.Status.DeepCopy()
+	bindErr := r.bind(ctx, sb, status)
+	if err := r.updateStatus(ctx, sb, status); err != nil {
+		return err
+	}
+	return bindErr
+}
+
+// bind resolves the service and binds each workload, recording the outcome in the status.
+func (r *Reconciler) bind(ctx context.Context, sb *v1alpha3.ServiceBinding, status *v1alpha3.ServiceBindingStatus) error {
+	status.ObservedGeneration = sb.Generation
+	if errs := sb.Validate(); len(errs) != 0 {
+		r.setCondition(sb, status, v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "Invalid", errs.ToAggregate().Error())
+		return nil
+	}
+
+	secret, reason, message, err := r.resolveSecret(ctx, sb)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		r.setCondition(sb, status, v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionFalse, reason, message)
+		r.setCondition(sb, status, v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, reason, message)
+		return nil
+	}
+	status.Binding = &v1alpha3.ServiceBindingSecretReference{Name: secret}
+	r.setCondition(sb, status, v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionTrue, "ResolvedSecret", "")
+
+	resolved := sb.DeepCopy()
+	resolved.Status = *status
+	b, err := resolved.ToBinding()
+	if err != nil {
+		return err
+	}
+
+	ref := sb.Spec.Workload
+	client, m, names, err := r.workloads(ctx, sb)
+	problem, ok := err.(*workloadProblem)
+	if err != nil && !ok {
+		return err
+	}
+	// the binding is removed from workloads it was applied to that are no longer referenced,
+	// before the binding is applied under what may be a new name
+	if err := r.unbindStale(ctx, sb.Namespace, status, b.Name, ref.APIVersion, ref.Kind, names); err != nil {
+		return err
+	}
+	if status.Applied == nil {
+		status.Applied = &v1alpha3.ServiceBindingApplied{Name: b.Name, APIVersion: ref.APIVersion, Kind: ref.Kind}
+	}
+
+	opts := r.Options
+	opts.SecretGetter = nil
+	if r.SecretGetter != nil {
+		opts.SecretGetter = r.SecretGetter(sb.Namespace)
+	}
+	failures := []string{}
+	reason = "WorkloadBound"
+	for _, name := range names {
+		var bindErr error
+		err := r.updateWorkload(ctx, client, name, func(obj *unstructured.Unstructured) (bool, error) {
+			result, err := b.Bind(obj, m, opts)
+			bindErr = err
+			return err == nil && result.Changed, nil
+		})
+		if err != nil {
+			return err
+		}
+		if bindErr != nil {
+			reason = "BindFailed"
+			failures = append(failures, fmt.Sprintf("%s %q: %s", ref.Kind, name, bindErr))
+			continue
+		}
+		status.Applied.Workloads = addString(status.Applied.Workloads, name)
+	}
+	if len(status.Applied.Workloads) == 0 {
+		status.Applied = nil
+	}
+	if problem != nil {
+		reason = problem.reason
+		failures = append(failures, problem.message)
+	}
+
+	if len(failures) != 0 {
+		message := strings.Join(failures, "; ")
+		r.setCondition(sb, status, v1alpha3.ServiceBindingConditionBound, metav1.ConditionFalse, reason, message)
+		r.setCondition(sb, status, v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, reason, message)
+		return nil
+	}
+	r.setCondition(sb, status, v1alpha3.ServiceBindingConditionBound, metav1.ConditionTrue, reason, "")
+	r.setCondition(sb, status, v1alpha3.ServiceBindingConditionReady, metav1.ConditionTrue, "Ready", "")
+	return nil
+}
+
+// unbindStale removes the binding recorded in the status from the workloads that are not
+// referenced by the name, kind and workload names the binding is now applied with. The record is
+// updated as each workload is unbound, so that a failure leaves the remaining workloads recorded.
+func (r *Reconciler) unbindStale(ctx context.Context, namespace string, status *v1alpha3.ServiceBindingStatus, name, apiVersion, kind string, workloads []string) error {
+	applied := status.Applied
+	if applied == nil {
+		return nil
+	}
+	current := applied.Name == name && applied.APIVersion == apiVersion && applied.Kind == kind
+	remaining := []string{}
+	for i, workload := range applied.Workloads {
+		if current && containsString(workloads, workload) {
+			remaining = append(remaining, workload)
+			continue
+		}
+		if err := r.unbindWorkload(ctx, namespace, applied, workload); err != nil {
+			applied.Workloads = append(remaining, applied.Workloads[i:]...)
+			return err
+		}
+	}
+	applied.Workloads = remaining
+	if !current {
+		status.Applied = nil
+	}
+	return nil
+}
+
+// unbind removes the ServiceBinding from each of its workloads. Workloads that no longer exist,
+// or are of a kind that cannot be bound, are ignored.
+func (r *Reconciler) unbind(ctx context.Context, sb *v1alpha3.ServiceBinding) error {
+	if applied := sb.Status.Applied; applied != nil {
+		for _, workload := range applied.Workloads {
+			if err := r.unbindWorkload(ctx, sb.Namespace, applied, workload); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// a ServiceBinding without a record may have been applied before the record was kept
+	if errs := sb.Validate(); len(errs) != 0 {
+		// an invalid ServiceBinding was never applied
+		return nil
+	}
+	applied := &v1alpha3.ServiceBindingApplied{
+		Name:       sb.BindingName(),
+		APIVersion: sb.Spec.Workload.APIVersion,
+		Kind:       sb.Spec.Workload.Kind,
+	}
+	_, _, names, err := r.workloads(ctx, sb)
+	if _, ok := err.(*workloadProblem); !ok && err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := r.unbindWorkload(ctx, sb.Namespace, applied, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unbindWorkload removes the applied binding from the named workload. A workload that no longer
+// exists, or is of a kind that cannot be bound, is ignored.
+func (r *Reconciler) unbindWorkload(ctx context.Context, namespace string, applied *v1alpha3.ServiceBindingApplied, name string) error {
+	client, m, err := r.workloadClient(namespace, applied.APIVersion, applied.Kind)
+	if _, ok := err.(*workloadProblem); ok {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	b := &binding.Binding{Name: applied.Name}
+	err = r.updateWorkload(ctx, client, name, func(obj *unstructured.Unstructured) (bool, error) {
+		result, err := b.Unbind(obj, m)
+		if err != nil {
+			return false, err
+		}
+		return result.Changed, nil
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// resolveSecret returns the name of the secret projected by the ServiceBinding. When the secret
+// cannot be resolved yet, the name is empty and the reason and message describe why.
+func (r *Reconciler) resolveSecret(ctx context.Context, sb *v1alpha3.ServiceBinding) (string, string, string, error) {
+	ref := sb.Spec.Service
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return "", "ServiceUnsupported", err.Error(), nil
+	}
+	resolver := &SecretResolver{Client: r.Client, Mapper: r.Mapper}
+	secret, err := resolver.ResolveSecret(ctx, gv.WithKind(ref.Kind), sb.Namespace, ref.Name)
+	switch {
+	case err == nil:
+		return secret.Name, "", "", nil
+	case apierrors.IsNotFound(err):
+		return "", "ServiceNotFound", fmt.Sprintf("%s %q not found", ref.Kind, ref.Name), nil
+	case IsServiceNotReady(err):
+		return "", "ServiceNotReady", err.Error(), nil
+	case meta.IsNoMatchError(err):
+		return "", "ServiceUnsupported", err.Error(), nil
+	default:
+		return "", "", "", err
+	}
+}
+
+// workloadProblem is a workload reference that cannot be bound, reported in the Bound
+// condition rather than retried.
+type workloadProblem struct {
+	reason  string
+	message string
+}
+
+func (p *workloadProblem) Error() string {
+	return p.message
+}
+
+// workloadClient returns the client and mapping of the workload kind. A kind that cannot be
+// bound is reported as a workloadProblem.
+func (r *Reconciler) workloadClient(namespace, apiVersion, kind string) (dynamic.ResourceInterface, *binding.PodMapping, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, nil, &workloadProblem{reason: "WorkloadUnsupported", message: err.Error()}
+	}
+	gvk := gv.WithKind(kind)
+	m, ok := r.Registry.Lookup(gvk)
+	if !ok {
+		return nil, nil, &workloadProblem{reason: "WorkloadUnsupported", message: fmt.Sprintf("no mapping for kind %s", gvk.GroupKind())}
+	}
+	mapping, err := r.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, nil, &workloadProblem{reason: "WorkloadUnsupported", message: err.Error()}
+	}
+	return r.Client.Resource(mapping.Resource).Namespace(namespace), m, nil
+}
+
+// workloads returns the client and mapping of the workload kind referenced by the
+// ServiceBinding, with the names of the workloads referenced by name or by label selector.
+func (r *Reconciler) workloads(ctx context.Context, sb *v1alpha3.ServiceBinding) (dynamic.ResourceInterface, *binding.PodMapping, []string, error) {
+	ref := sb.Spec.Workload
+	client, m, err := r.workloadClient(sb.Namespace, ref.APIVersion, ref.Kind)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if ref.Name != "" {
+		if _, err := client.Get(ctx, ref.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			return nil, nil, nil, &workloadProblem{reason: "WorkloadNotFound", message: fmt.Sprintf("%s %q not found", ref.Kind, ref.Name)}
+		} else if err != nil {
+			return nil, nil, nil, err
+		}
+		return client, m, []string{ref.Name}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ref.Selector)
+	if err != nil {
+		return nil, nil, nil, &workloadProblem{reason: "WorkloadUnsupported", message: err.Error()}
+	}
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	names := []string{}
+	for i := range list.Items {
+		names = append(names, list.Items[i].GetName())
+	}
+	return client, m, names, nil
+}
+
+// updateWorkload applies the mutation to the latest version of the workload, updating the
+// workload when it changed. The mutation is applied again if the workload is modified
+// concurrently.
+func (r *Reconciler) updateWorkload(ctx context.Context, client dynamic.ResourceInterface, name string, mutate func(obj *unstructured.Unstructured) (bool, error)) error {
+	return retry.RetryOnConflict(r.backoff(), func() error {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		changed, err := mutate(obj)
+		if err != nil || !changed {
+			return err
+		}
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// updateServiceBinding applies the mutation to the latest version of the ServiceBinding,
+// updating the ServiceBinding and sb with the result.
+func (r *Reconciler) updateServiceBinding(ctx context.Context, sb *v1alpha3.ServiceBinding, mutate func(latest *v1alpha3.ServiceBinding)) error {
+	client := r.Client.Resource(ServiceBindingResource).Namespace(sb.Namespace)
+	return retry.RetryOnConflict(r.backoff(), func() error {
+		u, err := client.Get(ctx, sb.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest, err := toServiceBinding(u)
+		if err != nil {
+			return err
+		}
+		mutate(latest)
+		if u, err = toUnstructured(latest); err != nil {
+			return err
+		}
+		u, err = client.Update(ctx, u, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		updated, err := toServiceBinding(u)
+		if err != nil {
+			return err
+		}
+		*sb = *updated
+		return nil
+	})
+}
+
+// updateStatus writes the status to the latest version of the ServiceBinding, unless it is
+// already up to date.
+func (r *Reconciler) updateStatus(ctx context.Context, sb *v1alpha3.ServiceBinding, status *v1alpha3.ServiceBindingStatus) error {
+	client := r.Client.Resource(ServiceBindingResource).Namespace(sb.Namespace)
+	return retry.RetryOnConflict(r.backoff(), func() error {
+		u, err := client.Get(ctx, sb.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		latest, err := toServiceBinding(u)
+		if err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(latest.Status, *status) {
+			return nil
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+		if err != nil {
+			return err
+		}
+		u.Object["status"] = content
+		_, err = client.UpdateStatus(ctx, u, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// setCondition sets the condition, retaining the last transition time when the status is
+// unchanged.
+func (r *Reconciler) setCondition(sb *v1alpha3.ServiceBinding, status *v1alpha3.ServiceBindingStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: sb.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+func (r *Reconciler) backoff() wait.Backoff {
+	if r.Backoff != nil {
+		return *r.Backoff
+	}
+	return retry.DefaultRetry
+}
+
+func toUnstructured(sb *v1alpha3.ServiceBinding) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(sb)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+func toServiceBinding(u *unstructured.Unstructured) (*v1alpha3.ServiceBinding, error) {
+	sb := &v1alpha3.ServiceBinding{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, sb); err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
+
+func hasFinalizer(sb *v1alpha3.ServiceBinding) bool {
+	return containsString(sb.Finalizers, v1alpha3.ServiceBindingFinalizer)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// addString adds the value to the sorted values, unless already present.
+func addString(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	values = append(values, value)
+	sort.Strings(values)
+	return values
+}
+
+func removeString(values []string, value string) []string {
+	out := []string{}
+	for _, v := range values {
+		if v != value {
+			out = append(out, v)
+		}
+	}
+	return out
+}
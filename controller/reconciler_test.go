@@ -0,0 +1,823 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	binding "github.com/scothis/unstructured-meta-binding"
+	"github.com/scothis/unstructured-meta-binding/apis/servicebinding/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+)
+
+var (
+	deploymentsResource  = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	statefulSetsResource = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	databasesResource    = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "databases"}
+	widgetsResource      = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+)
+
+func testMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, meta.RESTScopeNamespace)
+	mapper.Add(v1alpha3.SchemeGroupVersion.WithKind("ServiceBinding"), meta.RESTScopeNamespace)
+	return mapper
+}
+
+func deployment(name string, labels map[string]interface{}, volumes ...interface{}) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name":  "web",
+				"image": "web",
+			},
+		},
+	}
+	if len(volumes) != 0 {
+		spec["volumes"] = volumes
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"namespace": "default",
+				"name":      name,
+				"labels":    labels,
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": spec,
+				},
+			},
+		},
+	}
+}
+
+func database(secret string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Database",
+			"metadata": map[string]interface{}{
+				"namespace": "default",
+				"name":      "shop",
+			},
+		},
+	}
+	if secret != "" {
+		unstructured.SetNestedField(obj.Object, secret, "status", "binding", "name")
+	}
+	return obj
+}
+
+func serviceBinding(mutate func(sb *v1alpha3.ServiceBinding)) *unstructured.Unstructured {
+	sb := &v1alpha3.ServiceBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha3.SchemeGroupVersion.String(),
+			Kind:       "ServiceBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "db",
+			Generation: 1,
+		},
+		Spec: v1alpha3.ServiceBindingSpec{
+			Workload: v1alpha3.ServiceBindingWorkloadReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "web",
+			},
+			Service: v1alpha3.ServiceBindingServiceReference{
+				APIVersion: "example.com/v1",
+				Kind:       "Database",
+				Name:       "shop",
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(sb)
+	}
+	u, err := toUnstructured(sb)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func secret(namespace, name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Data: map[string][]byte{
+			"type": []byte("mysql"),
+		},
+	}
+}
+
+func condition(conditionType string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: 1,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// applied records the binding applied to the named Deployments.
+func applied(name string, workloads ...string) *v1alpha3.ServiceBindingApplied {
+	return &v1alpha3.ServiceBindingApplied{
+		Name:       name,
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Workloads:  workloads,
+	}
+}
+
+var boundVolumes = []corev1.Volume{
+	{
+		Name: "binding-db",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: "db-secret",
+			},
+		},
+	},
+}
+
+func TestReconciler(t *testing.T) {
+	ready := []metav1.Condition{
+		condition(v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionTrue, "ResolvedSecret", ""),
+		condition(v1alpha3.ServiceBindingConditionBound, metav1.ConditionTrue, "WorkloadBound", ""),
+		condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionTrue, "Ready", ""),
+	}
+
+	tests := []struct {
+		name               string
+		objects            map[schema.GroupVersionResource][]*unstructured.Unstructured
+		conflicts          map[schema.GroupVersionResource]int
+		secrets            []runtime.Object
+		expectedConditions []metav1.Condition
+		expectedSecret     string
+		expectedApplied    *v1alpha3.ServiceBindingApplied
+		expectedVolumes    map[string][]corev1.Volume
+		expectedActions    []string
+	}{
+		{
+			name: "binds workload",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				databasesResource:      {database("db-secret")},
+				deploymentsResource:    {deployment("web", nil)},
+			},
+			expectedConditions: ready,
+			expectedSecret:     "db-secret",
+			expectedApplied:    applied("db", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": boundVolumes,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update deployments web",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "binds workloads by selector",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(func(sb *v1alpha3.ServiceBinding) {
+					sb.Spec.Workload.Name = ""
+					sb.Spec.Workload.Selector = &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app.kubernetes.io/part-of": "shop"},
+					}
+				})},
+				databasesResource: {database("db-secret")},
+				deploymentsResource: {
+					deployment("cart", map[string]interface{}{"app.kubernetes.io/part-of": "shop"}),
+					deployment("web", map[string]interface{}{"app.kubernetes.io/part-of": "shop"}),
+					deployment("blog", map[string]interface{}{"app.kubernetes.io/part-of": "blog"}),
+				},
+			},
+			expectedConditions: ready,
+			expectedSecret:     "db-secret",
+			expectedApplied:    applied("db", "cart", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"cart": boundVolumes,
+				"web":  boundVolumes,
+				"blog": nil,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update deployments cart",
+				"update deployments web",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "direct secret reference",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(func(sb *v1alpha3.ServiceBinding) {
+					sb.Spec.Service = v1alpha3.ServiceBindingServiceReference{
+						APIVersion: "v1",
+						Kind:       "Secret",
+						Name:       "db-secret",
+					}
+				})},
+				deploymentsResource: {deployment("web", nil)},
+			},
+			expectedConditions: ready,
+			expectedSecret:     "db-secret",
+			expectedApplied:    applied("db", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": boundVolumes,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update deployments web",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "retries conflicting updates",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				databasesResource:      {database("db-secret")},
+				deploymentsResource:    {deployment("web", nil)},
+			},
+			conflicts: map[schema.GroupVersionResource]int{
+				deploymentsResource:    2,
+				ServiceBindingResource: 1,
+			},
+			expectedConditions: ready,
+			expectedSecret:     "db-secret",
+			expectedApplied:    applied("db", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": boundVolumes,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update servicebindings db",
+				"update deployments web",
+				"update deployments web",
+				"update deployments web",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "service not ready",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				databasesResource:      {database("")},
+				deploymentsResource:    {deployment("web", nil)},
+			},
+			expectedConditions: []metav1.Condition{
+				condition(v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionFalse, "ServiceNotReady", `Database "shop" is not ready: status.binding.name is not set`),
+				condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "ServiceNotReady", `Database "shop" is not ready: status.binding.name is not set`),
+			},
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": nil,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "service not found",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				deploymentsResource:    {deployment("web", nil)},
+			},
+			expectedConditions: []metav1.Condition{
+				condition(v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionFalse, "ServiceNotFound", `Database "shop" not found`),
+				condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "ServiceNotFound", `Database "shop" not found`),
+			},
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": nil,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "workload not found",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				databasesResource:      {database("db-secret")},
+			},
+			expectedConditions: []metav1.Condition{
+				condition(v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionTrue, "ResolvedSecret", ""),
+				condition(v1alpha3.ServiceBindingConditionBound, metav1.ConditionFalse, "WorkloadNotFound", `Deployment "web" not found`),
+				condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "WorkloadNotFound", `Deployment "web" not found`),
+			},
+			expectedSecret: "db-secret",
+			expectedActions: []string{
+				"update servicebindings db",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "workload kind without mapping",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(func(sb *v1alpha3.ServiceBinding) {
+					sb.Spec.Workload.APIVersion = "example.com/v1"
+					sb.Spec.Workload.Kind = "Widget"
+				})},
+				databasesResource: {database("db-secret")},
+			},
+			expectedConditions: []metav1.Condition{
+				condition(v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionTrue, "ResolvedSecret", ""),
+				condition(v1alpha3.ServiceBindingConditionBound, metav1.ConditionFalse, "WorkloadUnsupported", "no mapping for kind Widget.example.com"),
+				condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "WorkloadUnsupported", "no mapping for kind Widget.example.com"),
+			},
+			expectedSecret: "db-secret",
+			expectedActions: []string{
+				"update servicebindings db",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "bind failed",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				databasesResource:      {database("db-secret")},
+				deploymentsResource: {deployment("web", nil, map[string]interface{}{
+					"name":     "binding-db",
+					"emptyDir": map[string]interface{}{},
+				})},
+			},
+			expectedConditions: []metav1.Condition{
+				condition(v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionTrue, "ResolvedSecret", ""),
+				condition(v1alpha3.ServiceBindingConditionBound, metav1.ConditionFalse, "BindFailed", `Deployment "web": binding "db": volume "binding-db" owned by user: volume already exists`),
+				condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "BindFailed", `Deployment "web": binding "db": volume "binding-db" owned by user: volume already exists`),
+			},
+			expectedSecret: "db-secret",
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": {
+					{
+						Name: "binding-db",
+						VolumeSource: corev1.VolumeSource{
+							EmptyDir: &corev1.EmptyDirVolumeSource{},
+						},
+					},
+				},
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "secret not found",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				databasesResource:      {database("db-secret")},
+				deploymentsResource:    {deployment("web", nil)},
+			},
+			// the secret is checked in the namespace of the ServiceBinding
+			secrets: []runtime.Object{secret("other", "db-secret")},
+			expectedConditions: []metav1.Condition{
+				condition(v1alpha3.ServiceBindingConditionServiceAvailable, metav1.ConditionTrue, "ResolvedSecret", ""),
+				condition(v1alpha3.ServiceBindingConditionBound, metav1.ConditionFalse, "BindFailed", `Deployment "web": binding "db": secret "db-secret" not found`),
+				condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "BindFailed", `Deployment "web": binding "db": secret "db-secret" not found`),
+			},
+			expectedSecret: "db-secret",
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": nil,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "secret found",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(nil)},
+				databasesResource:      {database("db-secret")},
+				deploymentsResource:    {deployment("web", nil)},
+			},
+			secrets:            []runtime.Object{secret("default", "db-secret")},
+			expectedConditions: ready,
+			expectedSecret:     "db-secret",
+			expectedApplied:    applied("db", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": boundVolumes,
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update deployments web",
+				"update-status servicebindings db",
+			},
+		},
+		{
+			name: "invalid",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {serviceBinding(func(sb *v1alpha3.ServiceBinding) {
+					sb.Spec.Workload.Name = ""
+				})},
+			},
+			expectedConditions: []metav1.Condition{
+				condition(v1alpha3.ServiceBindingConditionReady, metav1.ConditionFalse, "Invalid", "spec.workload: Required value: one of name or selector is required"),
+			},
+			expectedActions: []string{
+				"update servicebindings db",
+				"update-status servicebindings db",
+			},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			client := newFakeClient(c.objects)
+			conflicts := map[schema.GroupVersionResource]*int{}
+			for gvr, n := range c.conflicts {
+				remaining := n
+				conflicts[gvr] = &remaining
+				conflictOnUpdate(client, gvr, &remaining)
+			}
+			r := &Reconciler{
+				Client:   client,
+				Mapper:   testMapper(),
+				Registry: binding.DefaultRegistry(),
+				Backoff:  &wait.Backoff{Steps: 5},
+			}
+			if c.secrets != nil {
+				secrets := kubernetesfake.NewSimpleClientset(c.secrets...).CoreV1()
+				r.SecretGetter = func(namespace string) binding.SecretGetter {
+					return binding.SecretGetterFromClient(secrets.Secrets(namespace))
+				}
+			}
+
+			if err := r.Reconcile(context.Background(), "default", "db"); err != nil {
+				t.Fatalf("Reconcile() unexpected err: %v", err)
+			}
+
+			sb, err := toServiceBinding(getObject(client, ServiceBindingResource, "default", "db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]string{v1alpha3.ServiceBindingFinalizer}, sb.Finalizers); diff != "" {
+				t.Errorf("Reconcile() finalizers (-expected, +actual): %s", diff)
+			}
+			if expected, actual := int64(1), sb.Status.ObservedGeneration; expected != actual {
+				t.Errorf("Reconcile() expected observed generation %d, got %d", expected, actual)
+			}
+			if diff := cmp.Diff(c.expectedConditions, sb.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("Reconcile() conditions (-expected, +actual): %s", diff)
+			}
+			secret := ""
+			if sb.Status.Binding != nil {
+				secret = sb.Status.Binding.Name
+			}
+			if expected, actual := c.expectedSecret, secret; expected != actual {
+				t.Errorf("Reconcile() expected secret %q, got %q", expected, actual)
+			}
+			if diff := cmp.Diff(c.expectedApplied, sb.Status.Applied); diff != "" {
+				t.Errorf("Reconcile() applied (-expected, +actual): %s", diff)
+			}
+			for name, expected := range c.expectedVolumes {
+				actual := workloadVolumes(t, getObject(client, deploymentsResource, "default", name))
+				if diff := cmp.Diff(expected, actual); diff != "" {
+					t.Errorf("Reconcile() volumes of %q (-expected, +actual): %s", name, diff)
+				}
+			}
+			if diff := cmp.Diff(c.expectedActions, writes(client)); diff != "" {
+				t.Errorf("Reconcile() actions (-expected, +actual): %s", diff)
+			}
+			for gvr, n := range conflicts {
+				if *n != 0 {
+					t.Errorf("Reconcile() expected %d more conflicting updates of %s", *n, gvr.Resource)
+				}
+			}
+
+			// reconciling again finds everything up to date
+			client.ClearActions()
+			if err := r.Reconcile(context.Background(), "default", "db"); err != nil {
+				t.Fatalf("Reconcile() unexpected err: %v", err)
+			}
+			if actions := writes(client); len(actions) != 0 {
+				t.Errorf("Reconcile() expected no actions when up to date, got %v", actions)
+			}
+		})
+	}
+}
+
+func TestReconciler_Stale(t *testing.T) {
+	shop := map[string]interface{}{"app.kubernetes.io/part-of": "shop"}
+	bySelector := func(sb *v1alpha3.ServiceBinding) {
+		sb.Spec.Workload.Name = ""
+		sb.Spec.Workload.Selector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app.kubernetes.io/part-of": "shop"},
+		}
+	}
+	updateSpec := func(mutate func(spec *v1alpha3.ServiceBindingSpec)) func(t *testing.T, client *dynamicfake.FakeDynamicClient) {
+		return func(t *testing.T, client *dynamicfake.FakeDynamicClient) {
+			sb, err := toServiceBinding(getObject(client, ServiceBindingResource, "default", "db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mutate(&sb.Spec)
+			sb.Generation++
+			u, err := toUnstructured(sb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.Resource(ServiceBindingResource).Namespace("default").Update(context.Background(), u, metav1.UpdateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name            string
+		serviceBinding  *unstructured.Unstructured
+		deployments     []*unstructured.Unstructured
+		change          func(t *testing.T, client *dynamicfake.FakeDynamicClient)
+		expectedApplied *v1alpha3.ServiceBindingApplied
+		expectedVolumes map[string][]corev1.Volume
+	}{
+		{
+			name:           "renamed",
+			serviceBinding: serviceBinding(nil),
+			deployments:    []*unstructured.Unstructured{deployment("web", nil)},
+			change: updateSpec(func(spec *v1alpha3.ServiceBindingSpec) {
+				spec.Name = "database"
+			}),
+			expectedApplied: applied("database", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": {
+					{
+						Name: "binding-database",
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{
+								SecretName: "db-secret",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:           "retargeted",
+			serviceBinding: serviceBinding(nil),
+			deployments:    []*unstructured.Unstructured{deployment("web", nil), deployment("api", nil)},
+			change: updateSpec(func(spec *v1alpha3.ServiceBindingSpec) {
+				spec.Workload.Name = "api"
+			}),
+			expectedApplied: applied("db", "api"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": nil,
+				"api": boundVolumes,
+			},
+		},
+		{
+			name:           "retargeted to a missing workload",
+			serviceBinding: serviceBinding(nil),
+			deployments:    []*unstructured.Unstructured{deployment("web", nil)},
+			change: updateSpec(func(spec *v1alpha3.ServiceBindingSpec) {
+				spec.Workload.Name = "api"
+			}),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": nil,
+			},
+		},
+		{
+			name:           "no longer selected",
+			serviceBinding: serviceBinding(bySelector),
+			deployments:    []*unstructured.Unstructured{deployment("web", shop), deployment("api", shop)},
+			change: func(t *testing.T, client *dynamicfake.FakeDynamicClient) {
+				api := getObject(client, deploymentsResource, "default", "api")
+				api.SetLabels(map[string]string{"app.kubernetes.io/part-of": "blog"})
+				if _, err := client.Resource(deploymentsResource).Namespace("default").Update(context.Background(), api, metav1.UpdateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expectedApplied: applied("db", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": boundVolumes,
+				"api": nil,
+			},
+		},
+		{
+			name:           "no longer exists",
+			serviceBinding: serviceBinding(bySelector),
+			deployments:    []*unstructured.Unstructured{deployment("web", shop), deployment("api", shop)},
+			change: func(t *testing.T, client *dynamicfake.FakeDynamicClient) {
+				if err := client.Resource(deploymentsResource).Namespace("default").Delete(context.Background(), "api", metav1.DeleteOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expectedApplied: applied("db", "web"),
+			expectedVolumes: map[string][]corev1.Volume{
+				"web": boundVolumes,
+			},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			client := newFakeClient(map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				ServiceBindingResource: {c.serviceBinding},
+				databasesResource:      {database("db-secret")},
+				deploymentsResource:    c.deployments,
+			})
+			r := &Reconciler{
+				Client:   client,
+				Mapper:   testMapper(),
+				Registry: binding.DefaultRegistry(),
+			}
+			ctx := context.Background()
+			if err := r.Reconcile(ctx, "default", "db"); err != nil {
+				t.Fatalf("Reconcile() unexpected err: %v", err)
+			}
+			c.change(t, client)
+			if err := r.Reconcile(ctx, "default", "db"); err != nil {
+				t.Fatalf("Reconcile() unexpected err: %v", err)
+			}
+
+			sb, err := toServiceBinding(getObject(client, ServiceBindingResource, "default", "db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expectedApplied, sb.Status.Applied); diff != "" {
+				t.Errorf("Reconcile() applied (-expected, +actual): %s", diff)
+			}
+			for name, expected := range c.expectedVolumes {
+				actual := workloadVolumes(t, getObject(client, deploymentsResource, "default", name))
+				if diff := cmp.Diff(expected, actual); diff != "" {
+					t.Errorf("Reconcile() volumes of %q (-expected, +actual): %s", name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestReconciler_Deleted(t *testing.T) {
+	client := newFakeClient(map[schema.GroupVersionResource][]*unstructured.Unstructured{
+		ServiceBindingResource: {serviceBinding(nil)},
+		databasesResource:      {database("db-secret")},
+		deploymentsResource:    {deployment("web", nil)},
+	})
+	r := &Reconciler{
+		Client:   client,
+		Mapper:   testMapper(),
+		Registry: binding.DefaultRegistry(),
+	}
+	ctx := context.Background()
+	if err := r.Reconcile(ctx, "default", "db"); err != nil {
+		t.Fatalf("Reconcile() unexpected err: %v", err)
+	}
+	if diff := cmp.Diff(boundVolumes, workloadVolumes(t, getObject(client, deploymentsResource, "default", "web"))); diff != "" {
+		t.Fatalf("Reconcile() volumes (-expected, +actual): %s", diff)
+	}
+
+	sb := getObject(client, ServiceBindingResource, "default", "db")
+	now := metav1.NewTime(time.Now())
+	sb.SetDeletionTimestamp(&now)
+	if _, err := client.Resource(ServiceBindingResource).Namespace("default").Update(ctx, sb, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	client.ClearActions()
+	if err := r.Reconcile(ctx, "default", "db"); err != nil {
+		t.Fatalf("Reconcile() unexpected err: %v", err)
+	}
+
+	if diff := cmp.Diff([]corev1.Volume(nil), workloadVolumes(t, getObject(client, deploymentsResource, "default", "web"))); diff != "" {
+		t.Errorf("Reconcile() volumes (-expected, +actual): %s", diff)
+	}
+	if finalizers := getObject(client, ServiceBindingResource, "default", "db").GetFinalizers(); len(finalizers) != 0 {
+		t.Errorf("Reconcile() expected ServiceBinding to be released, got finalizers %v", finalizers)
+	}
+	expectedActions := []string{
+		"update deployments web",
+		"update servicebindings db",
+	}
+	if diff := cmp.Diff(expectedActions, writes(client)); diff != "" {
+		t.Errorf("Reconcile() actions (-expected, +actual): %s", diff)
+	}
+
+	// a released ServiceBinding, and one that no longer exists, are ignored
+	client.ClearActions()
+	if err := r.Reconcile(ctx, "default", "db"); err != nil {
+		t.Errorf("Reconcile() unexpected err: %v", err)
+	}
+	if err := client.Resource(ServiceBindingResource).Namespace("default").Delete(ctx, "db", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reconcile(ctx, "default", "db"); err != nil {
+		t.Errorf("Reconcile() unexpected err: %v", err)
+	}
+	if actions := writes(client); len(actions) != 0 {
+		t.Errorf("Reconcile() expected no actions, got %v", actions)
+	}
+}
+
+func TestReconciler_DeletedUnapplied(t *testing.T) {
+	// a ServiceBinding deleted before it was applied unbinds the workloads it currently matches,
+	// workloads that were never bound are left alone
+	now := metav1.NewTime(time.Now())
+	client := newFakeClient(map[schema.GroupVersionResource][]*unstructured.Unstructured{
+		ServiceBindingResource: {serviceBinding(func(sb *v1alpha3.ServiceBinding) {
+			sb.Finalizers = []string{v1alpha3.ServiceBindingFinalizer}
+			sb.DeletionTimestamp = &now
+		})},
+		databasesResource:   {database("db-secret")},
+		deploymentsResource: {deployment("web", nil)},
+	})
+	r := &Reconciler{
+		Client:   client,
+		Mapper:   testMapper(),
+		Registry: binding.DefaultRegistry(),
+	}
+	ctx := context.Background()
+	if err := r.Reconcile(ctx, "default", "db"); err != nil {
+		t.Fatalf("Reconcile() unexpected err: %v", err)
+	}
+
+	if finalizers := getObject(client, ServiceBindingResource, "default", "db").GetFinalizers(); len(finalizers) != 0 {
+		t.Errorf("Reconcile() expected ServiceBinding to be released, got finalizers %v", finalizers)
+	}
+	expectedActions := []string{
+		"update servicebindings db",
+	}
+	if diff := cmp.Diff(expectedActions, writes(client)); diff != "" {
+		t.Errorf("Reconcile() actions (-expected, +actual): %s", diff)
+	}
+}
+
+func TestReconciler_Run(t *testing.T) {
+	client := newFakeClient(map[schema.GroupVersionResource][]*unstructured.Unstructured{
+		databasesResource:   {database("db-secret")},
+		deploymentsResource: {deployment("web", nil)},
+	})
+	r := &Reconciler{
+		Client:   client,
+		Mapper:   testMapper(),
+		Registry: binding.DefaultRegistry(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- r.Run(ctx, "default", time.Minute)
+	}()
+
+	watching := func() (bool, error) {
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "watch" {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, watching); err != nil {
+		t.Fatalf("Run() expected to watch ServiceBindings: %v", err)
+	}
+	// a ServiceBinding created once the watch has started
+	if _, err := client.Resource(ServiceBindingResource).Namespace("default").Create(ctx, serviceBinding(nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(writes(client)) == 3, nil
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() unexpected err: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("Run() expected ServiceBinding to be reconciled, got actions %v", writes(client))
+	}
+}
+
+// workloadVolumes returns the volumes of the workload's pod template.
+func workloadVolumes(t *testing.T, obj *unstructured.Unstructured) []corev1.Volume {
+	m := &binding.PodMapping{}
+	m.Default()
+	mpt, err := m.ToMeta(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mpt.Volumes) == 0 {
+		return nil
+	}
+	return mpt.Volumes
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ServiceNotReadyError is returned while a ProvisionedService has not published the name of its
+// secret. The service should be resolved again once its status changes.
+type ServiceNotReadyError struct {
+	// Kind of the service.
+	Kind schema.GroupVersionKind
+	// Namespace of the service.
+	Namespace string
+	// Name of the service.
+	Name string
+}
+
+func (e *ServiceNotReadyError) Error() string {
+	return fmt.Sprintf("%s %q is not ready: status.binding.name is not set", e.Kind.Kind, e.Name)
+}
+
+// IsServiceNotReady returns true if the error, or an error it wraps, is a ServiceNotReadyError.
+func IsServiceNotReady(err error) bool {
+	var notReady *ServiceNotReadyError
+	return errors.As(err, &notReady)
+}
+
+// SecretResolver resolves the secret of a service. Any resource may be a service, a
+// ProvisionedService, by naming its secret with `status.binding.name`.
+type SecretResolver struct {
+	// Client reads the services.
+	Client dynamic.Interface
+	// Mapper resolves the resource of the service kinds.
+	Mapper meta.RESTMapper
+}
+
+// ResolveSecret returns a reference to the secret of the named service. A Secret is its own
+// service and is not read. A ServiceNotReadyError is returned until the service names its secret,
+// while a service that does not exist returns a NotFound API error.
+func (r *SecretResolver) ResolveSecret(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string) (corev1.LocalObjectReference, error) {
+	if gvk.Group == "" && gvk.Version == "v1" && gvk.Kind == "Secret" {
+		return corev1.LocalObjectReference{Name: name}, nil
+	}
+	mapping, err := r.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return corev1.LocalObjectReference{}, err
+	}
+	service, err := r.Client.Resource(mapping.Resource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return corev1.LocalObjectReference{}, err
+	}
+	secret, _, err := unstructured.NestedString(service.Object, "status", "binding", "name")
+	if err != nil {
+		return corev1.LocalObjectReference{}, fmt.Errorf("%s %q: %w", gvk.Kind, name, err)
+	}
+	if secret == "" {
+		return corev1.LocalObjectReference{}, &ServiceNotReadyError{
+			Kind:      gvk,
+			Namespace: namespace,
+			Name:      name,
+		}
+	}
+	return corev1.LocalObjectReference{Name: secret}, nil
+}
+
+// Resolve sets the binding's secret to the secret of the named service. The binding is not
+// modified when an error is returned.
+func (r *SecretResolver) Resolve(ctx context.Context, b *binding.Binding, gvk schema.GroupVersionKind, namespace, name string) error {
+	secret, err := r.ResolveSecret(ctx, gvk, namespace, name)
+	if err != nil {
+		return err
+	}
+	b.Secret = secret
+	return nil
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	binding "github.com/scothis/unstructured-meta-binding"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestSecretResolver(t *testing.T) {
+	databaseKind := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"}
+
+	tests := []struct {
+		name        string
+		objects     map[schema.GroupVersionResource][]*unstructured.Unstructured
+		kind        schema.GroupVersionKind
+		serviceName string
+		// getErr fails every get made with the client
+		getErr       error
+		expected     corev1.LocalObjectReference
+		expectedErr  func(error) bool
+		expectedGets []string
+	}{
+		{
+			name: "provisioned service",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				databasesResource: {database("db-secret")},
+			},
+			kind:         databaseKind,
+			serviceName:  "shop",
+			expected:     corev1.LocalObjectReference{Name: "db-secret"},
+			expectedGets: []string{"databases default/shop"},
+		},
+		{
+			name:        "direct secret reference",
+			kind:        corev1.SchemeGroupVersion.WithKind("Secret"),
+			serviceName: "db-secret",
+			expected:    corev1.LocalObjectReference{Name: "db-secret"},
+			// the secret is not read
+			expectedGets: []string{},
+		},
+		{
+			name: "service not ready",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				databasesResource: {database("")},
+			},
+			kind:         databaseKind,
+			serviceName:  "shop",
+			expectedErr:  IsServiceNotReady,
+			expectedGets: []string{"databases default/shop"},
+		},
+		{
+			name:         "service not found",
+			kind:         databaseKind,
+			serviceName:  "shop",
+			expectedErr:  apierrors.IsNotFound,
+			expectedGets: []string{"databases default/shop"},
+		},
+		{
+			name: "service forbidden",
+			objects: map[schema.GroupVersionResource][]*unstructured.Unstructured{
+				databasesResource: {database("db-secret")},
+			},
+			kind:         databaseKind,
+			serviceName:  "shop",
+			getErr:       apierrors.NewForbidden(databasesResource.GroupResource(), "shop", fmt.Errorf("denied")),
+			expectedErr:  apierrors.IsForbidden,
+			expectedGets: []string{"databases default/shop"},
+		},
+		{
+			name:         "unknown kind",
+			kind:         schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Queue"},
+			serviceName:  "shop",
+			expectedErr:  meta.IsNoMatchError,
+			expectedGets: []string{},
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			client := newFakeClient(c.objects)
+			if c.getErr != nil {
+				client.PrependReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, c.getErr
+				})
+			}
+			resolver := &SecretResolver{
+				Client: client,
+				Mapper: testMapper(),
+			}
+			actual, err := resolver.ResolveSecret(context.Background(), c.kind, "default", c.serviceName)
+			gets := []string{}
+			for _, action := range client.Actions() {
+				if get, ok := action.(clienttesting.GetAction); ok {
+					gets = append(gets, fmt.Sprintf("%s %s/%s", get.GetResource().Resource, get.GetNamespace(), get.GetName()))
+				}
+			}
+			if diff := cmp.Diff(c.expectedGets, gets); diff != "" {
+				t.Errorf("ResolveSecret() gets (-expected, +actual): %s", diff)
+			}
+			if c.expectedErr != nil {
+				if !c.expectedErr(err) {
+					t.Fatalf("ResolveSecret() unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSecret() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("ResolveSecret() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestSecretResolver_Resolve(t *testing.T) {
+	databaseKind := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"}
+	resolver := &SecretResolver{
+		Client: newFakeClient(map[schema.GroupVersionResource][]*unstructured.Unstructured{
+			databasesResource: {database("db-secret")},
+		}),
+		Mapper: testMapper(),
+	}
+
+	b := binding.Binding{Name: "db"}
+	if err := resolver.Resolve(context.Background(), &b, databaseKind, "default", "shop"); err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	expected := binding.Binding{Name: "db", Secret: corev1.LocalObjectReference{Name: "db-secret"}}
+	if diff := cmp.Diff(expected, b); diff != "" {
+		t.Errorf("Resolve() (-expected, +actual): %s", diff)
+	}
+
+	notReady := &SecretResolver{
+		Client: newFakeClient(map[schema.GroupVersionResource][]*unstructured.Unstructured{
+			databasesResource: {database("")},
+		}),
+		Mapper: testMapper(),
+	}
+	b = binding.Binding{Name: "db"}
+	err := notReady.Resolve(context.Background(), &b, databaseKind, "default", "shop")
+	if !IsServiceNotReady(err) {
+		t.Fatalf("Resolve() expected ServiceNotReadyError, got: %v", err)
+	}
+	if expected := `Database "shop" is not ready: status.binding.name is not set`; err.Error() != expected {
+		t.Errorf("Resolve() error = %q, expected %q", err.Error(), expected)
+	}
+	if diff := cmp.Diff(binding.Binding{Name: "db"}, b); diff != "" {
+		t.Errorf("Resolve() modified binding (-expected, +actual): %s", diff)
+	}
+}
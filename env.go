@@ -0,0 +1,150 @@
+package binding
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// insertEnv adds the injected environment variables to env. Kubernetes only expands a
+// `$(VAR_NAME)` reference to a variable defined earlier in the list, so each injected variable is
+// placed ahead of the first entry that references it. All other entries keep their relative
+// order.
+//
+// An injected variable that is already defined with the same value is not duplicated, while a
+// conflicting definition or a circular reference is an error.
+func insertEnv(env []corev1.EnvVar, injected ...corev1.EnvVar) ([]corev1.EnvVar, error) {
+	all := make([]corev1.EnvVar, 0, len(env)+len(injected))
+	all = append(all, env...)
+	isInjected := map[string]bool{}
+	for _, e := range injected {
+		if i := indexEnv(all, e.Name); i >= 0 {
+			if !equality.Semantic.DeepEqual(all[i], e) {
+				return nil, fmt.Errorf("conflicting definitions for environment variable %q", e.Name)
+			}
+			continue
+		}
+		isInjected[e.Name] = true
+		all = append(all, e)
+	}
+	if len(all) == len(env) {
+		// nothing to insert, leave the existing order alone
+		return all, nil
+	}
+
+	byName := map[string][]int{}
+	for i, e := range all {
+		byName[e.Name] = append(byName[e.Name], i)
+	}
+	// only references to or from an injected variable constrain the order, references between
+	// existing entries are left exactly as the user wrote them
+	deps := func(i int) []int {
+		d := []int{}
+		for _, ref := range envReferences(all[i].Value) {
+			if ref == all[i].Name {
+				continue
+			}
+			if !isInjected[ref] && !isInjected[all[i].Name] {
+				continue
+			}
+			d = append(d, byName[ref]...)
+		}
+		return d
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(all))
+	out := make([]corev1.EnvVar, 0, len(all))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("circular reference to environment variable %q", all[i].Name)
+		}
+		state[i] = visiting
+		for _, d := range deps(i) {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		out = append(out, all[i])
+		return nil
+	}
+	for i := range all {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// projectedEnv returns the environment variables projected from the binding's secret. The
+// binding's Type and Provider, when set, are projected as literal values in place of the `type`
+// and `provider` entries of the secret.
+func (b *Binding) projectedEnv() ([]corev1.EnvVar, error) {
+	env := make([]corev1.EnvVar, 0, len(b.Env))
+	for _, m := range b.Env {
+		switch {
+		case m.Key == "type" && b.Type != "":
+			env = append(env, corev1.EnvVar{Name: m.Name, Value: b.Type})
+		case m.Key == "provider" && b.Provider != "":
+			env = append(env, corev1.EnvVar{Name: m.Name, Value: b.Provider})
+		case b.Secret.Name == "":
+			return nil, fmt.Errorf("environment variable %q: no secret to project key %q from", m.Name, m.Key)
+		default:
+			env = append(env, corev1.EnvVar{
+				Name: m.Name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: b.Secret,
+						Key:                  m.Key,
+					},
+				},
+			})
+		}
+	}
+	return env, nil
+}
+
+// indexEnv returns the index of the first environment variable with the name, or -1 if not
+// found.
+func indexEnv(env []corev1.EnvVar, name string) int {
+	for i := range env {
+		if env[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// envReferences returns the names of variables referenced by `$(VAR_NAME)` expressions within
+// the value. Escaped references, `$$(VAR_NAME)`, are ignored.
+func envReferences(value string) []string {
+	refs := []string{}
+	for i := 0; i < len(value)-1; i++ {
+		if value[i] != '$' {
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			i++
+		case '(':
+			for j := i + 2; j < len(value); j++ {
+				if value[j] == ')' {
+					refs = append(refs, value[i+2:j])
+					i = j
+					break
+				}
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,223 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestInsertEnv(t *testing.T) {
+	rootEnv := corev1.EnvVar{
+		Name:  "SERVICE_BINDING_ROOT",
+		Value: "/bindings",
+	}
+
+	tests := []struct {
+		name        string
+		env         []corev1.EnvVar
+		injected    []corev1.EnvVar
+		expected    []corev1.EnvVar
+		expectedErr bool
+	}{
+		{
+			name:     "empty",
+			env:      []corev1.EnvVar{},
+			injected: []corev1.EnvVar{},
+			expected: []corev1.EnvVar{},
+		},
+		{
+			name: "append unreferenced",
+			env: []corev1.EnvVar{
+				{Name: "A", Value: "a"},
+			},
+			injected: []corev1.EnvVar{rootEnv},
+			expected: []corev1.EnvVar{
+				{Name: "A", Value: "a"},
+				rootEnv,
+			},
+		},
+		{
+			name: "insert ahead of reference",
+			env: []corev1.EnvVar{
+				{Name: "A", Value: "a"},
+				{Name: "B", Value: "$(SERVICE_BINDING_ROOT)/b"},
+				{Name: "C", Value: "c"},
+				{Name: "D", Value: "$(SERVICE_BINDING_ROOT)/d"},
+			},
+			injected: []corev1.EnvVar{rootEnv},
+			expected: []corev1.EnvVar{
+				{Name: "A", Value: "a"},
+				rootEnv,
+				{Name: "B", Value: "$(SERVICE_BINDING_ROOT)/b"},
+				{Name: "C", Value: "c"},
+				{Name: "D", Value: "$(SERVICE_BINDING_ROOT)/d"},
+			},
+		},
+		{
+			name: "escaped reference",
+			env: []corev1.EnvVar{
+				{Name: "A", Value: "$$(SERVICE_BINDING_ROOT)/a"},
+			},
+			injected: []corev1.EnvVar{rootEnv},
+			expected: []corev1.EnvVar{
+				{Name: "A", Value: "$$(SERVICE_BINDING_ROOT)/a"},
+				rootEnv,
+			},
+		},
+		{
+			name: "injected references injected",
+			env: []corev1.EnvVar{
+				{Name: "A", Value: "$(DB)"},
+			},
+			injected: []corev1.EnvVar{
+				{Name: "DB", Value: "$(SERVICE_BINDING_ROOT)/db"},
+				rootEnv,
+			},
+			expected: []corev1.EnvVar{
+				rootEnv,
+				{Name: "DB", Value: "$(SERVICE_BINDING_ROOT)/db"},
+				{Name: "A", Value: "$(DB)"},
+			},
+		},
+		{
+			name: "injected references existing",
+			env: []corev1.EnvVar{
+				{Name: "A", Value: "$(DB)"},
+				{Name: "HOST", Value: "localhost"},
+			},
+			injected: []corev1.EnvVar{
+				{Name: "DB", Value: "$(HOST)/db"},
+			},
+			expected: []corev1.EnvVar{
+				{Name: "HOST", Value: "localhost"},
+				{Name: "DB", Value: "$(HOST)/db"},
+				{Name: "A", Value: "$(DB)"},
+			},
+		},
+		{
+			name: "existing forward references are untouched",
+			env: []corev1.EnvVar{
+				{Name: "A", Value: "$(B)"},
+				{Name: "B", Value: "b"},
+			},
+			injected: []corev1.EnvVar{rootEnv},
+			expected: []corev1.EnvVar{
+				{Name: "A", Value: "$(B)"},
+				{Name: "B", Value: "b"},
+				rootEnv,
+			},
+		},
+		{
+			name: "self reference",
+			env:  []corev1.EnvVar{},
+			injected: []corev1.EnvVar{
+				{Name: "PATH", Value: "$(PATH):/bin"},
+			},
+			expected: []corev1.EnvVar{
+				{Name: "PATH", Value: "$(PATH):/bin"},
+			},
+		},
+		{
+			name: "identical duplicate",
+			env: []corev1.EnvVar{
+				rootEnv,
+			},
+			injected: []corev1.EnvVar{rootEnv},
+			expected: []corev1.EnvVar{
+				rootEnv,
+			},
+		},
+		{
+			name: "conflicting duplicate",
+			env: []corev1.EnvVar{
+				{Name: "SERVICE_BINDING_ROOT", Value: "/custom/path"},
+			},
+			injected:    []corev1.EnvVar{rootEnv},
+			expectedErr: true,
+		},
+		{
+			name: "conflicting injected duplicate",
+			env:  []corev1.EnvVar{},
+			injected: []corev1.EnvVar{
+				rootEnv,
+				{Name: "SERVICE_BINDING_ROOT", Value: "/custom/path"},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "cycle",
+			env: []corev1.EnvVar{
+				{Name: "A", Value: "$(B)"},
+			},
+			injected: []corev1.EnvVar{
+				{Name: "B", Value: "$(A)"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := insertEnv(c.env, c.injected...)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("insertEnv() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("insertEnv() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestEnvReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: []string{},
+		},
+		{
+			name:     "literal",
+			value:    "hello",
+			expected: []string{},
+		},
+		{
+			name:     "references",
+			value:    "$(A)/$(B)",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "escaped",
+			value:    "$$(A)/$(B)",
+			expected: []string{"B"},
+		},
+		{
+			name:     "unterminated",
+			value:    "$(A",
+			expected: []string{},
+		},
+		{
+			name:     "trailing dollar",
+			value:    "$(A)$",
+			expected: []string{"A"},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := envReferences(c.value)
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("envReferences() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package binding
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// PointerStatus is the outcome of resolving a JSON Pointer against an object.
+type PointerStatus string
+
+const (
+	// PointerResolved is a pointer referencing a value of the expected type.
+	PointerResolved PointerStatus = "Resolved"
+	// PointerMissing is a pointer referencing a value that does not exist. Missing values are
+	// created when the object is bound.
+	PointerMissing PointerStatus = "Missing"
+	// PointerTypeMismatch is a pointer referencing, or traversing, a value of an unexpected
+	// type. Binding an object with a mismatched pointer fails or discards the value.
+	PointerTypeMismatch PointerStatus = "TypeMismatch"
+)
+
+// Explanation describes how a PodMapping is evaluated against an object. It is intended to help
+// debug custom mappings, where a misaligned path or pointer silently discovers nothing.
+type Explanation struct {
+	// Annotations traces the PodMapping's Annotations pointer.
+	Annotations PointerTrace `json:"annotations"`
+	// Labels traces the PodMapping's Labels pointer. Nil when the mapping has no Labels.
+	// +optional
+	Labels *PointerTrace `json:"labels,omitempty"`
+	// Containers traces each ContainerMapping, in order.
+	Containers []ContainerMappingTrace `json:"containers"`
+	// Volumes traces the PodMapping's Volumes pointer.
+	Volumes PointerTrace `json:"volumes"`
+}
+
+type ContainerMappingTrace struct {
+	// Path is the ContainerMapping's JSONPath query.
+	Path string `json:"path"`
+	// Matches is the number of nodes matched by the query.
+	Matches int `json:"matches"`
+	// Message describes why the query did not match, if known.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Nodes traces each matched node, in order.
+	Nodes []NodeTrace `json:"nodes"`
+}
+
+type NodeTrace struct {
+	// Location is a JSON Pointer to the node within the object.
+	Location string `json:"location"`
+	// Name traces the ContainerMapping's Name pointer. Nil when the mapping has no Name.
+	// +optional
+	Name *PointerTrace `json:"name,omitempty"`
+	// Env traces the ContainerMapping's Env pointer.
+	Env PointerTrace `json:"env"`
+	// VolumeMounts traces the ContainerMapping's VolumeMounts pointer.
+	VolumeMounts PointerTrace `json:"volumeMounts"`
+}
+
+type PointerTrace struct {
+	// Pointer is the JSON Pointer as defined by the mapping, relative to the object or the
+	// matched node.
+	Pointer string `json:"pointer"`
+	// Location is the JSON Pointer resolved from the root of the object.
+	Location string `json:"location"`
+	// Status is the outcome of resolving the pointer.
+	Status PointerStatus `json:"status"`
+	// Value is the value found, if any.
+	// +optional
+	Value interface{} `json:"value,omitempty"`
+	// Message describes why the pointer is missing or mismatched.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Explain evaluates the mapping against the object, reporting the nodes matched by each
+// ContainerMapping and how each pointer resolves. Unlike ToMeta, invalid queries and mismatched
+// pointers are reported in the explanation rather than returned as an error. The mapping should
+// be defaulted.
+func (m *PodMapping) Explain(obj runtime.Object) (*Explanation, error) {
+	u, err := runtime.DefaultUnstructuredConverter.
+		ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	locations := locate(u)
+
+	e := &Explanation{
+		Annotations: m.trace(m.Annotations, u, "", &map[string]string{}),
+		Containers:  make([]ContainerMappingTrace, len(m.Containers)),
+		Volumes:     m.trace(m.Volumes, u, "", &[]corev1.Volume{}),
+	}
+	if m.Labels != "" {
+		labels := m.trace(m.Labels, u, "", &map[string]string{})
+		e.Labels = &labels
+	}
+	for i := range m.Containers {
+		cm := &m.Containers[i]
+		ct := ContainerMappingTrace{
+			Path:  cm.Path,
+			Nodes: []NodeTrace{},
+		}
+		cp := jsonpath.New("")
+		if err := cp.Parse(fmt.Sprintf("{%s}", cm.Path)); err != nil {
+			ct.Message = err.Error()
+			e.Containers[i] = ct
+			continue
+		}
+		cr, err := cp.FindResults(u)
+		if err != nil {
+			ct.Message = err.Error()
+			e.Containers[i] = ct
+			continue
+		}
+		for _, cv := range cr[0] {
+			location := locations[identity(cv)]
+			node := cv.Interface()
+			nt := NodeTrace{
+				Location:     location,
+				Env:          m.trace(cm.Env, node, location, &[]corev1.EnvVar{}),
+				VolumeMounts: m.trace(cm.VolumeMounts, node, location, &[]corev1.VolumeMount{}),
+			}
+			if cm.Name != "" {
+				name := m.trace(cm.Name, node, location, new(string))
+				nt.Name = &name
+			}
+			ct.Nodes = append(ct.Nodes, nt)
+		}
+		ct.Matches = len(ct.Nodes)
+		e.Containers[i] = ct
+	}
+
+	return e, nil
+}
+
+// trace resolves the pointer against the value located at base, checking the value found can
+// be decoded into the target.
+func (m *PodMapping) trace(ptr string, value interface{}, base string, target interface{}) PointerTrace {
+	t := PointerTrace{
+		Pointer:  ptr,
+		Location: base,
+	}
+	for _, key := range m.keys(ptr) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			t.Location = t.Location + "/" + escapePointer(key)
+			next, ok := v[key]
+			if !ok || next == nil {
+				t.Status = PointerMissing
+				t.Message = fmt.Sprintf("no value at %q", t.Location)
+				return t
+			}
+			value = next
+		default:
+			t.Status = PointerTypeMismatch
+			t.Value = value
+			t.Message = fmt.Sprintf("expected object at %q, found %s", t.Location, describeKind(value))
+			return t
+		}
+	}
+	t.Value = value
+	b, err := json.Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(b, target)
+	}
+	if err != nil {
+		t.Status = PointerTypeMismatch
+		t.Message = fmt.Sprintf("expected %s at %q: %s", reflect.TypeOf(target).Elem(), t.Location, err)
+		return t
+	}
+	t.Status = PointerResolved
+	return t
+}
+
+// describeKind names the JSON type of an unstructured value.
+func describeKind(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int64, float64:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
@@ -0,0 +1,276 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPodMapping_Explain(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  PodMapping
+		seed     runtime.Object
+		expected *Explanation
+	}{
+		{
+			name: "resolved",
+			mapping: PodMapping{
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+			},
+			seed: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"template": map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"key": "value",
+								},
+							},
+							"spec": map[string]interface{}{
+								"containers": []interface{}{
+									map[string]interface{}{
+										"name": "hello",
+										"env": []interface{}{
+											map[string]interface{}{
+												"name":  "GREETING",
+												"value": "hi",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &Explanation{
+				Annotations: PointerTrace{
+					Pointer:  "/spec/template/metadata/annotations",
+					Location: "/spec/template/metadata/annotations",
+					Status:   PointerResolved,
+					Value: map[string]interface{}{
+						"key": "value",
+					},
+				},
+				Labels: &PointerTrace{
+					Pointer:  "/spec/template/metadata/labels",
+					Location: "/spec/template/metadata/labels",
+					Status:   PointerMissing,
+					Message:  `no value at "/spec/template/metadata/labels"`,
+				},
+				Containers: []ContainerMappingTrace{
+					{
+						Path:    ".spec.template.spec.containers[*]",
+						Matches: 1,
+						Nodes: []NodeTrace{
+							{
+								Location: "/spec/template/spec/containers/0",
+								Name: &PointerTrace{
+									Pointer:  "/name",
+									Location: "/spec/template/spec/containers/0/name",
+									Status:   PointerResolved,
+									Value:    "hello",
+								},
+								Env: PointerTrace{
+									Pointer:  "/env",
+									Location: "/spec/template/spec/containers/0/env",
+									Status:   PointerResolved,
+									Value: []interface{}{
+										map[string]interface{}{
+											"name":  "GREETING",
+											"value": "hi",
+										},
+									},
+								},
+								VolumeMounts: PointerTrace{
+									Pointer:  "/volumeMounts",
+									Location: "/spec/template/spec/containers/0/volumeMounts",
+									Status:   PointerMissing,
+									Message:  `no value at "/spec/template/spec/containers/0/volumeMounts"`,
+								},
+							},
+						},
+					},
+				},
+				Volumes: PointerTrace{
+					Pointer:  "/spec/template/spec/volumes",
+					Location: "/spec/template/spec/volumes",
+					Status:   PointerMissing,
+					Message:  `no value at "/spec/template/spec/volumes"`,
+				},
+			},
+		},
+		{
+			name: "misaligned path",
+			mapping: PodMapping{
+				Annotations: "/spec/annotations",
+				Labels:      "/spec/labels",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.containers[*]",
+					},
+					{
+						Path: ".spec.containers[",
+					},
+				},
+				Volumes: "/spec/volumes",
+			},
+			seed: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"template": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"containers": []interface{}{
+									map[string]interface{}{
+										"name": "hello",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &Explanation{
+				Annotations: PointerTrace{
+					Pointer:  "/spec/annotations",
+					Location: "/spec/annotations",
+					Status:   PointerMissing,
+					Message:  `no value at "/spec/annotations"`,
+				},
+				Labels: &PointerTrace{
+					Pointer:  "/spec/labels",
+					Location: "/spec/labels",
+					Status:   PointerMissing,
+					Message:  `no value at "/spec/labels"`,
+				},
+				Containers: []ContainerMappingTrace{
+					{
+						Path:    ".spec.containers[*]",
+						Message: "containers is not found",
+						Nodes:   []NodeTrace{},
+					},
+					{
+						Path:    ".spec.containers[",
+						Message: "unterminated array",
+						Nodes:   []NodeTrace{},
+					},
+				},
+				Volumes: PointerTrace{
+					Pointer:  "/spec/volumes",
+					Location: "/spec/volumes",
+					Status:   PointerMissing,
+					Message:  `no value at "/spec/volumes"`,
+				},
+			},
+		},
+		{
+			name: "type mismatch",
+			mapping: PodMapping{
+				Annotations: "/metadata/name/annotations",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/volumes",
+			},
+			seed: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "my-workload",
+					},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name": int64(1),
+								"env": map[string]interface{}{
+									"GREETING": "hi",
+								},
+								"volumeMounts": []interface{}{},
+							},
+						},
+						"volumes": []interface{}{},
+					},
+				},
+			},
+			expected: &Explanation{
+				Annotations: PointerTrace{
+					Pointer:  "/metadata/name/annotations",
+					Location: "/metadata/name",
+					Status:   PointerTypeMismatch,
+					Value:    "my-workload",
+					Message:  `expected object at "/metadata/name", found string`,
+				},
+				Labels: &PointerTrace{
+					Pointer:  "/metadata/name/labels",
+					Location: "/metadata/name",
+					Status:   PointerTypeMismatch,
+					Value:    "my-workload",
+					Message:  `expected object at "/metadata/name", found string`,
+				},
+				Containers: []ContainerMappingTrace{
+					{
+						Path:    ".spec.containers[*]",
+						Matches: 1,
+						Nodes: []NodeTrace{
+							{
+								Location: "/spec/containers/0",
+								Name: &PointerTrace{
+									Pointer:  "/name",
+									Location: "/spec/containers/0/name",
+									Status:   PointerTypeMismatch,
+									Value:    int64(1),
+									Message:  `expected string at "/spec/containers/0/name": json: cannot unmarshal number into Go value of type string`,
+								},
+								Env: PointerTrace{
+									Pointer:  "/env",
+									Location: "/spec/containers/0/env",
+									Status:   PointerTypeMismatch,
+									Value: map[string]interface{}{
+										"GREETING": "hi",
+									},
+									Message: `expected []v1.EnvVar at "/spec/containers/0/env": json: cannot unmarshal object into Go value of type []v1.EnvVar`,
+								},
+								VolumeMounts: PointerTrace{
+									Pointer:  "/volumeMounts",
+									Location: "/spec/containers/0/volumeMounts",
+									Status:   PointerResolved,
+									Value:    []interface{}{},
+								},
+							},
+						},
+					},
+				},
+				Volumes: PointerTrace{
+					Pointer:  "/spec/volumes",
+					Location: "/spec/volumes",
+					Status:   PointerResolved,
+					Value:    []interface{}{},
+				},
+			},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			m := &c.mapping
+			m.Default()
+			actual, err := m.Explain(c.seed)
+			if err != nil {
+				t.Fatalf("Explain() unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Explain() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -2,9 +2,23 @@ module github.com/scothis/unstructured-meta-binding
 
 go 1.16
 
+// The indirect requirements raise dependencies of client-go, and of its fake clients used by
+// the controller tests, above the pseudo-versions client-go requires. Those versions are
+// not served by the module mirror the builds use, so each is pinned to the oldest release it
+// does serve. They can be dropped once client-go is upgraded.
 require (
+	github.com/evanphx/json-patch v4.12.0+incompatible // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/go-cmp v0.5.6
+	github.com/googleapis/gnostic v0.5.5 // indirect
+	golang.org/x/oauth2 v0.0.0-20210323180902-22b0adad7558 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/term v0.0.0-20210422114643-f5beecf764ed // indirect
+	google.golang.org/protobuf v1.27.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/api v0.21.3
 	k8s.io/apimachinery v0.21.3
 	k8s.io/client-go v0.21.2
+	k8s.io/utils v0.0.0-20210111153108-fddb29f9d009 // indirect
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,226 @@
+package binding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// MappingCandidate is a PodMapping proposed for a kind of resource by inspecting an example of
+// that resource. Candidates are a starting point and should be reviewed, for example with
+// PodMapping.Explain, before they are registered.
+type MappingCandidate struct {
+	// Mapping is the proposed PodMapping, already defaulted.
+	Mapping PodMapping
+	// Location is a JSON Pointer to the pod spec like structure the mapping was inferred from.
+	Location string
+	// Confidence ranks the candidate, between 0 and 1. Higher is more likely to be correct.
+	Confidence float64
+	// Reasons describes the evidence for the candidate.
+	Reasons []string
+}
+
+// InferMapping proposes PodMappings for the example object, most confident first. Any object
+// holding one or more arrays of objects with both a `name` and an `image` is treated as a pod
+// spec, while sibling `volumes` and nearby `metadata` strengthen the candidate. An object with
+// nothing resembling a pod spec has no candidates.
+func InferMapping(obj runtime.Object) ([]MappingCandidate, error) {
+	u, err := runtime.DefaultUnstructuredConverter.
+		ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []MappingCandidate{}
+	var walk func(v interface{}, n *node)
+	walk = func(v interface{}, n *node) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if c, ok := inferCandidate(t, n); ok {
+				candidates = append(candidates, c)
+			}
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(t[k], n.field(k, t))
+			}
+		case []interface{}:
+			for i, e := range t {
+				walk(e, n.index(i))
+			}
+		}
+	}
+	walk(u, &node{})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+	return candidates, nil
+}
+
+// InferMappingFromYAML proposes PodMappings for the example object encoded as YAML or JSON.
+func InferMappingFromYAML(data []byte) ([]MappingCandidate, error) {
+	u := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &u); err != nil {
+		return nil, err
+	}
+	return InferMapping(&unstructured.Unstructured{Object: u})
+}
+
+// node tracks the location of a value as both a JSON Pointer and a JSONPath query. The object
+// holding a field is retained to inspect the field's siblings.
+type node struct {
+	pointer string
+	path    string
+	holder  *node
+	fields  map[string]interface{}
+	// indexed is true if the location is within an array
+	indexed bool
+}
+
+func (n *node) field(key string, fields map[string]interface{}) *node {
+	return &node{
+		pointer: n.pointer + "/" + escapePointer(key),
+		path:    n.path + jsonPathField(key),
+		holder:  &node{pointer: n.pointer, fields: fields},
+		indexed: n.indexed,
+	}
+}
+
+func (n *node) index(i int) *node {
+	return &node{
+		pointer: fmt.Sprintf("%s/%d", n.pointer, i),
+		path:    fmt.Sprintf("%s[%d]", n.path, i),
+		indexed: true,
+	}
+}
+
+// jsonPathField returns the JSONPath child operator for the key. Dots within the key are
+// escaped, as the bracket notation does not preserve them.
+func jsonPathField(key string) string {
+	return "." + strings.ReplaceAll(key, ".", `\.`)
+}
+
+// wellKnownContainers is the role of the fields holding containers in a corev1.PodSpec.
+var wellKnownContainers = []struct {
+	field string
+	role  string
+}{
+	{field: "initContainers", role: RoleInit},
+	{field: "containers", role: RoleApp},
+	{field: "ephemeralContainers", role: RoleEphemeral},
+}
+
+// inferCandidate proposes a mapping if the object located by the node resembles a pod spec.
+func inferCandidate(obj map[string]interface{}, n *node) (MappingCandidate, bool) {
+	fields := []string{}
+	for k, v := range obj {
+		if isContainerArray(v) {
+			fields = append(fields, k)
+		}
+	}
+	if len(fields) == 0 {
+		return MappingCandidate{}, false
+	}
+	sort.Strings(fields)
+
+	c := MappingCandidate{
+		Location: n.pointer,
+		Reasons:  []string{},
+	}
+	m := &c.Mapping
+	// each piece of evidence scores a point towards the confidence
+	score, maxScore := 2, 5
+	standard := contains(fields, "containers")
+	if standard {
+		// a pod spec may omit the optional container fields
+		score++
+		c.Reasons = append(c.Reasons, fmt.Sprintf("containers found at %q", n.pointer+"/containers"))
+		for _, wk := range wellKnownContainers {
+			m.Containers = append(m.Containers, ContainerMapping{
+				Path: n.path + jsonPathField(wk.field) + "[*]",
+				Name: "/name",
+				Role: wk.role,
+			})
+		}
+	}
+	for _, field := range fields {
+		if standard && isWellKnownContainers(field) {
+			continue
+		}
+		c.Reasons = append(c.Reasons, fmt.Sprintf("containers found at %q", n.pointer+"/"+escapePointer(field)))
+		m.Containers = append(m.Containers, ContainerMapping{
+			Path: n.path + jsonPathField(field) + "[*]",
+			Name: "/name",
+			Role: RoleApp,
+		})
+	}
+
+	m.Volumes = n.pointer + "/volumes"
+	if _, ok := obj["volumes"].([]interface{}); ok {
+		score++
+		c.Reasons = append(c.Reasons, fmt.Sprintf("volumes found at %q", m.Volumes))
+	}
+
+	// the metadata of a pod template, or of a pod, is a sibling of its spec. A pod spec held
+	// directly by an array has no siblings and is given its own metadata.
+	metadata := n.pointer + "/metadata"
+	var siblings map[string]interface{}
+	if n.holder != nil {
+		metadata = n.holder.pointer + "/metadata"
+		siblings = n.holder.fields
+	}
+	m.Annotations = metadata + "/annotations"
+	m.Labels = metadata + "/labels"
+	if _, ok := siblings["metadata"].(map[string]interface{}); ok {
+		score++
+		c.Reasons = append(c.Reasons, fmt.Sprintf("metadata found at %q", metadata))
+	}
+
+	if n.indexed {
+		score--
+		c.Reasons = append(c.Reasons, "located within an array, which the pointers cannot traverse")
+	}
+
+	c.Confidence = float64(score) / float64(maxScore)
+	m.Default()
+	return c, true
+}
+
+func isWellKnownContainers(field string) bool {
+	for _, wk := range wellKnownContainers {
+		if wk.field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// isContainerArray returns true if the value is a non-empty array of objects that each have a
+// string name and image.
+func isContainerArray(v interface{}) bool {
+	items, ok := v.([]interface{})
+	if !ok || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := obj["name"].(string); !ok {
+			return false
+		}
+		if _, ok := obj["image"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInferMapping(t *testing.T) {
+	seed := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": "hello",
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "hello",
+							Image: "hello:latest",
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "data",
+						},
+					},
+				},
+			},
+		},
+	}
+	expected, _ := DefaultRegistry().Lookup(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+
+	actual, err := InferMapping(seed)
+	if err != nil {
+		t.Fatalf("InferMapping() unexpected err: %v", err)
+	}
+	if len(actual) != 1 {
+		t.Fatalf("InferMapping() expected 1 candidate, got %d", len(actual))
+	}
+	if diff := cmp.Diff(*expected, actual[0].Mapping); diff != "" {
+		t.Errorf("InferMapping() (-expected, +actual): %s", diff)
+	}
+	if actual[0].Confidence != 1 {
+		t.Errorf("InferMapping() expected confidence 1, got %v", actual[0].Confidence)
+	}
+}
+
+func TestInferMappingFromYAML(t *testing.T) {
+	tests := []struct {
+		name        string
+		seed        string
+		expected    []MappingCandidate
+		expectedErr bool
+	}{
+		{
+			name: "ranked candidates",
+			seed: `
+apiVersion: example.com/v1
+kind: Cluster
+metadata:
+  name: my-cluster
+spec:
+  leader:
+    metadata:
+      annotations:
+        role: leader
+    spec:
+      containers:
+      - name: leader
+        image: leader:latest
+      volumes: []
+  workers:
+  - sidecars:
+    - name: proxy
+      image: proxy:latest
+    workload.containers:
+    - name: worker
+      image: worker:latest
+`,
+			expected: []MappingCandidate{
+				{
+					Mapping: PodMapping{
+						Annotations: "/spec/leader/metadata/annotations",
+						Labels:      "/spec/leader/metadata/labels",
+						Containers: []ContainerMapping{
+							{
+								Path:         ".spec.leader.spec.initContainers[*]",
+								Name:         "/name",
+								Env:          "/env",
+								VolumeMounts: "/volumeMounts",
+								Role:         RoleInit,
+							},
+							{
+								Path:         ".spec.leader.spec.containers[*]",
+								Name:         "/name",
+								Env:          "/env",
+								VolumeMounts: "/volumeMounts",
+								Role:         RoleApp,
+							},
+							{
+								Path:         ".spec.leader.spec.ephemeralContainers[*]",
+								Name:         "/name",
+								Env:          "/env",
+								VolumeMounts: "/volumeMounts",
+								Role:         RoleEphemeral,
+							},
+						},
+						Volumes: "/spec/leader/spec/volumes",
+					},
+					Location:   "/spec/leader/spec",
+					Confidence: 1,
+					Reasons: []string{
+						`containers found at "/spec/leader/spec/containers"`,
+						`volumes found at "/spec/leader/spec/volumes"`,
+						`metadata found at "/spec/leader/metadata"`,
+					},
+				},
+				{
+					Mapping: PodMapping{
+						Annotations: "/spec/workers/0/metadata/annotations",
+						Labels:      "/spec/workers/0/metadata/labels",
+						Containers: []ContainerMapping{
+							{
+								Path:         ".spec.workers[0].sidecars[*]",
+								Name:         "/name",
+								Env:          "/env",
+								VolumeMounts: "/volumeMounts",
+								Role:         RoleApp,
+							},
+							{
+								Path:         ".spec.workers[0].workload\\.containers[*]",
+								Name:         "/name",
+								Env:          "/env",
+								VolumeMounts: "/volumeMounts",
+								Role:         RoleApp,
+							},
+						},
+						Volumes: "/spec/workers/0/volumes",
+					},
+					Location:   "/spec/workers/0",
+					Confidence: 0.2,
+					Reasons: []string{
+						`containers found at "/spec/workers/0/sidecars"`,
+						`containers found at "/spec/workers/0/workload.containers"`,
+						"located within an array, which the pointers cannot traverse",
+					},
+				},
+			},
+		},
+		{
+			name: "no pod spec",
+			seed: `
+apiVersion: v1
+kind: ConfigMap
+data:
+  containers: hello
+`,
+			expected: []MappingCandidate{},
+		},
+		{
+			name:        "malformed",
+			seed:        `[`,
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := InferMappingFromYAML([]byte(c.seed))
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("InferMappingFromYAML() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("InferMappingFromYAML() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -16,6 +16,12 @@ type PodMapping struct {
 	// referenced value must be `map[string]string` on the discovered container. If the value
 	// does not exist it will be created.
 	Annotations string
+	// Labels is a JSON Pointer to the field holding the pod template's labels. The referenced
+	// value must be `map[string]string`. If the value does not exist it will be created.
+	// Defaults to the `labels` sibling of Annotations when Annotations points to an `annotations`
+	// field, otherwise the pod template's labels are not mapped.
+	// +optional
+	Labels string
 	// Containers defines mappings for containers.
 	Containers []ContainerMapping
 	// Volumes is a JSON Pointer to the field holding the container's environment variables. The
@@ -28,15 +34,25 @@ func (m *PodMapping) Default() {
 	if m.Annotations == "" {
 		m.Annotations = "/spec/template/metadata/annotations"
 	}
+	if m.Labels == "" && strings.HasPrefix(m.Annotations, "/") && strings.HasSuffix(m.Annotations, "/annotations") {
+		m.Labels = strings.TrimSuffix(m.Annotations, "/annotations") + "/labels"
+	}
 	if len(m.Containers) == 0 {
 		m.Containers = []ContainerMapping{
 			{
 				Path: ".spec.template.spec.initContainers[*]",
 				Name: "/name",
+				Role: RoleInit,
 			},
 			{
 				Path: ".spec.template.spec.containers[*]",
 				Name: "/name",
+				Role: RoleApp,
+			},
+			{
+				Path: ".spec.template.spec.ephemeralContainers[*]",
+				Name: "/name",
+				Role: RoleEphemeral,
 			},
 		}
 	}
@@ -48,6 +64,16 @@ func (m *PodMapping) Default() {
 	}
 }
 
+const (
+	// RoleInit labels init containers.
+	RoleInit = "init"
+	// RoleApp labels application containers.
+	RoleApp = "app"
+	// RoleEphemeral labels ephemeral debug containers. Ephemeral containers are only bound when a
+	// binding's ContainerSelection opts in to the role.
+	RoleEphemeral = "ephemeral"
+)
+
 type ContainerMapping struct {
 	// Path is a JSONPath query for containers on the resource. The query is executed
 	// from the root of the object and is not required to return any results.
@@ -66,6 +92,10 @@ type ContainerMapping struct {
 	// does not exist it will be created.
 	// +optional
 	VolumeMounts string
+	// Role labels the discovered containers, for example RoleInit or RoleApp. Bindings may
+	// select containers by role.
+	// +optional
+	Role string
 }
 
 func (m *ContainerMapping) Default() {
@@ -90,10 +120,16 @@ func (m *PodMapping) ToMeta(obj runtime.Object) (MetaPodTemplate, error) {
 		return mpt, err
 	}
 	uv := reflect.ValueOf(u)
+	locations := locate(u)
 
 	if err := m.getAt(m.Annotations, uv, &mpt.Annotations); err != nil {
 		return mpt, err
 	}
+	if m.Labels != "" {
+		if err := m.getAt(m.Labels, uv, &mpt.Labels); err != nil {
+			return mpt, err
+		}
+	}
 	for i := range m.Containers {
 		cp := jsonpath.New("")
 		if err := cp.Parse(fmt.Sprintf("{%s}", m.Containers[i].Path)); err != nil {
@@ -107,6 +143,9 @@ func (m *PodMapping) ToMeta(obj runtime.Object) (MetaPodTemplate, error) {
 		for _, cv := range cr[0] {
 			mc := MetaContainer{
 				Name:         "",
+				Mapping:      i,
+				Location:     locations[identity(cv)],
+				Role:         m.Containers[i].Role,
 				Env:          []corev1.EnvVar{},
 				VolumeMounts: []corev1.VolumeMount{},
 			}
@@ -146,6 +185,12 @@ func (m *PodMapping) FromMeta(obj runtime.Object, mpt MetaPodTemplate) error {
 	if err := m.setAt(m.Annotations, &mpt.Annotations, uv); err != nil {
 		return err
 	}
+	if m.Labels != "" && mpt.Labels != nil {
+		// labels are only written once they exist, or were changed
+		if err := m.setAt(m.Labels, &mpt.Labels, uv); err != nil {
+			return err
+		}
+	}
 	ci := 0
 	for i := range m.Containers {
 		cp := jsonpath.New("")
@@ -229,6 +274,44 @@ func (m *PodMapping) setAt(ptr string, value interface{}, target reflect.Value)
 	return nil
 }
 
+// locate returns the JSON Pointer of each object and array within the value, keyed by identity.
+func locate(value interface{}) map[uintptr]string {
+	locations := map[uintptr]string{}
+	var walk func(v interface{}, ptr string)
+	walk = func(v interface{}, ptr string) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			locations[identity(reflect.ValueOf(t))] = ptr
+			for k, e := range t {
+				walk(e, ptr+"/"+escapePointer(k))
+			}
+		case []interface{}:
+			for i, e := range t {
+				walk(e, fmt.Sprintf("%s/%d", ptr, i))
+			}
+		}
+	}
+	walk(value, "")
+	return locations
+}
+
+// identity returns a value that is shared by all references to the same map, or zero for other
+// kinds of values.
+func identity(v reflect.Value) uintptr {
+	for v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Map {
+		return 0
+	}
+	return v.Pointer()
+}
+
+// escapePointer escapes a key for use as a JSON Pointer reference token.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
 func (m *PodMapping) keys(ptr string) []string {
 	// TODO use a real json pointer parser, this does not support escaped sequences
 	ptr = strings.TrimPrefix(ptr, "/")
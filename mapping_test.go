@@ -1,6 +1,7 @@
 package binding
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -8,6 +9,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -77,21 +79,33 @@ func TestMapping_ToMeta(t *testing.T) {
 				Containers: []MetaContainer{
 					{
 						Name:         "init-hello",
+						Mapping:      0,
+						Location:     "/spec/template/spec/initContainers/0",
+						Role:         RoleInit,
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
 					{
 						Name:         "init-hello-2",
+						Mapping:      0,
+						Location:     "/spec/template/spec/initContainers/1",
+						Role:         RoleInit,
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
 					{
 						Name:         "hello",
+						Mapping:      1,
+						Location:     "/spec/template/spec/containers/0",
+						Role:         RoleApp,
 						Env:          []corev1.EnvVar{testEnv},
 						VolumeMounts: []corev1.VolumeMount{testVolumeMount},
 					},
 					{
 						Name:         "hello-2",
+						Mapping:      1,
+						Location:     "/spec/template/spec/containers/1",
+						Role:         RoleApp,
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
@@ -154,21 +168,29 @@ func TestMapping_ToMeta(t *testing.T) {
 				Containers: []MetaContainer{
 					{
 						Name:         "init-hello",
+						Mapping:      0,
+						Location:     "/spec/jobTemplate/spec/template/spec/initContainers/0",
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
 					{
 						Name:         "init-hello-2",
+						Mapping:      0,
+						Location:     "/spec/jobTemplate/spec/template/spec/initContainers/1",
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
 					{
 						Name:         "hello",
+						Mapping:      1,
+						Location:     "/spec/jobTemplate/spec/template/spec/containers/0",
 						Env:          []corev1.EnvVar{testEnv},
 						VolumeMounts: []corev1.VolumeMount{testVolumeMount},
 					},
 					{
 						Name:         "hello-2",
+						Mapping:      1,
+						Location:     "/spec/jobTemplate/spec/template/spec/containers/1",
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
@@ -176,6 +198,29 @@ func TestMapping_ToMeta(t *testing.T) {
 				Volumes: []corev1.Volume{testVolume},
 			},
 		},
+		{
+			name:    "labels",
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app": "shop",
+							},
+						},
+					},
+				},
+			},
+			expected: MetaPodTemplate{
+				Annotations: map[string]string{},
+				Labels: map[string]string{
+					"app": "shop",
+				},
+				Containers: []MetaContainer{},
+				Volumes:    []corev1.Volume{},
+			},
+		},
 		{
 			name:    "no containers",
 			mapping: PodMapping{},
@@ -205,6 +250,9 @@ func TestMapping_ToMeta(t *testing.T) {
 				Containers: []MetaContainer{
 					{
 						Name:         "",
+						Mapping:      1,
+						Location:     "/spec/template/spec/containers/0",
+						Role:         RoleApp,
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
@@ -294,6 +342,8 @@ func TestMapping_ToMeta(t *testing.T) {
 				Containers: []MetaContainer{
 					{
 						Name:         "",
+						Mapping:      0,
+						Location:     "/spec/template/spec/containers/0",
 						Env:          []corev1.EnvVar{},
 						VolumeMounts: []corev1.VolumeMount{},
 					},
@@ -559,6 +609,46 @@ func TestMapping_FromMeta(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "labels",
+			mapping: PodMapping{},
+			metadata: MetaPodTemplate{
+				Annotations: map[string]string{},
+				Labels: map[string]string{
+					"app":                     "shop",
+					"bindings.example.com/db": "true",
+				},
+				Containers: []MetaContainer{},
+				Volumes:    []corev1.Volume{},
+			},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app": "shop",
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app":                     "shop",
+								"bindings.example.com/db": "true",
+							},
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Volumes: []corev1.Volume{},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:    "no containers",
 			mapping: PodMapping{},
@@ -625,6 +715,114 @@ func TestMapping_FromMeta(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "almost podspecable, without labels",
+			mapping: PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name: "/name",
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			metadata: MetaPodTemplate{
+				Annotations: testAnnotations,
+				Containers: []MetaContainer{
+					{
+						Name:         "hello",
+						Env:          []corev1.EnvVar{},
+						VolumeMounts: []corev1.VolumeMount{},
+					},
+				},
+				Volumes: []corev1.Volume{},
+			},
+			seed: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "batch/v1",
+					"kind":       "CronJob",
+					"spec": map[string]interface{}{
+						"jobTemplate": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"template": map[string]interface{}{
+									"spec": map[string]interface{}{
+										"containers": []interface{}{
+											map[string]interface{}{"name": "hello"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "batch/v1",
+					"kind":       "CronJob",
+					"spec": map[string]interface{}{
+						"jobTemplate": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"template": map[string]interface{}{
+									"metadata": map[string]interface{}{
+										"annotations": map[string]interface{}{
+											"key": "value",
+										},
+									},
+									"spec": map[string]interface{}{
+										"containers": []interface{}{
+											map[string]interface{}{
+												"name":         "hello",
+												"env":          []interface{}{},
+												"volumeMounts": []interface{}{},
+											},
+										},
+										"volumes": []interface{}{},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "labels not mapped",
+			mapping: PodMapping{
+				Annotations: "/spec/podAnnotations",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.containers[*]",
+					},
+				},
+				Volumes: "/spec/volumes",
+			},
+			metadata: MetaPodTemplate{
+				Annotations: map[string]string{},
+				Labels: map[string]string{
+					"app": "shop",
+				},
+				Containers: []MetaContainer{},
+				Volumes:    []corev1.Volume{},
+			},
+			seed: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "example.com/v1",
+					"kind":       "Widget",
+				},
+			},
+			expected: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "example.com/v1",
+					"kind":       "Widget",
+					"spec": map[string]interface{}{
+						"podAnnotations": map[string]interface{}{},
+						"volumes":        []interface{}{},
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range tests {
@@ -650,3 +848,30 @@ func TestMapping_FromMeta(t *testing.T) {
 		})
 	}
 }
+
+func TestLocate(t *testing.T) {
+	container := map[string]interface{}{
+		"name": "hello",
+	}
+	escaped := map[string]interface{}{}
+	u := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{},
+				container,
+			},
+			"a/b~c": escaped,
+		},
+	}
+
+	locations := locate(u)
+	if expected, actual := "/spec/containers/1", locations[identity(reflect.ValueOf(container))]; expected != actual {
+		t.Errorf("locate() expected container at %q, got %q", expected, actual)
+	}
+	if expected, actual := "/spec/a~1b~0c", locations[identity(reflect.ValueOf(escaped))]; expected != actual {
+		t.Errorf("locate() expected escaped key at %q, got %q", expected, actual)
+	}
+	if expected, actual := "", locations[identity(reflect.ValueOf(u))]; expected != actual {
+		t.Errorf("locate() expected root at %q, got %q", expected, actual)
+	}
+}
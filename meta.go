@@ -6,12 +6,73 @@ import (
 
 type MetaPodTemplate struct {
 	Annotations map[string]string
-	Containers  []MetaContainer
-	Volumes     []corev1.Volume
+	// Labels is nil when the pod template has no labels, or the mapping does not map them.
+	Labels     map[string]string
+	Containers []MetaContainer
+	Volumes    []corev1.Volume
 }
 
 type MetaContainer struct {
 	Name         string
 	Env          []corev1.EnvVar
 	VolumeMounts []corev1.VolumeMount
+
+	// Mapping is the index of the ContainerMapping that discovered the container.
+	Mapping int
+	// Location is a JSON Pointer to the container within the object.
+	Location string
+	// Role is the label of the ContainerMapping that discovered the container.
+	Role string
+}
+
+// DeepCopy returns a copy of the MetaPodTemplate sharing no state with the original.
+func (in *MetaPodTemplate) DeepCopy() *MetaPodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := &MetaPodTemplate{
+		Annotations: copyStringMap(in.Annotations),
+		Labels:      copyStringMap(in.Labels),
+	}
+	if in.Containers != nil {
+		out.Containers = make([]MetaContainer, len(in.Containers))
+		for i := range in.Containers {
+			in.Containers[i].DeepCopyInto(&out.Containers[i])
+		}
+	}
+	if in.Volumes != nil {
+		out.Volumes = make([]corev1.Volume, len(in.Volumes))
+		for i := range in.Volumes {
+			in.Volumes[i].DeepCopyInto(&out.Volumes[i])
+		}
+	}
+	return out
+}
+
+// DeepCopyInto copies the MetaContainer into out, sharing no state with the original.
+func (in *MetaContainer) DeepCopyInto(out *MetaContainer) {
+	*out = *in
+	if in.Env != nil {
+		out.Env = make([]corev1.EnvVar, len(in.Env))
+		for i := range in.Env {
+			in.Env[i].DeepCopyInto(&out.Env[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		out.VolumeMounts = make([]corev1.VolumeMount, len(in.VolumeMounts))
+		for i := range in.VolumeMounts {
+			in.VolumeMounts[i].DeepCopyInto(&out.VolumeMounts[i])
+		}
+	}
+}
+
+func copyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
 }
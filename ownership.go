@@ -0,0 +1,168 @@
+package binding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// BindingsAnnotation is the pod template annotation recording the bindings applied to a
+// workload. The value is a JSON object keyed by binding name.
+const BindingsAnnotation = "meta-binding.scothis.github.io/bindings"
+
+// ChecksumAnnotationPrefix prefixes the pod template annotation holding the checksum of a
+// binding's secret. The binding name completes the annotation key.
+const ChecksumAnnotationPrefix = "checksum.meta-binding.scothis.github.io/"
+
+// TypeAnnotationPrefix prefixes the pod template annotation holding a binding's Type, which is
+// projected into the binding's volume in place of the secret's `type` entry. The binding name
+// completes the annotation key.
+const TypeAnnotationPrefix = "type.meta-binding.scothis.github.io/"
+
+// ProviderAnnotationPrefix prefixes the pod template annotation holding a binding's Provider,
+// which is projected into the binding's volume in place of the secret's `provider` entry. The
+// binding name completes the annotation key.
+const ProviderAnnotationPrefix = "provider.meta-binding.scothis.github.io/"
+
+// bindingRecord is the ownership marker written for each applied binding. Entries not listed
+// in a record are owned by the user and are never removed or rewritten.
+type bindingRecord struct {
+	// Volume is the name of the volume projecting the binding's secret.
+	Volume string `json:"volume,omitempty"`
+	// Env is the names of the environment variables owned by the binding, keyed by container
+	// name. An environment variable may be owned by more than one binding.
+	Env map[string][]string `json:"env,omitempty"`
+	// VolumeMounts is the paths of the volume mounts owned by the binding, keyed by container
+	// name.
+	VolumeMounts map[string][]string `json:"volumeMounts,omitempty"`
+	// Label is the key of the pod template label owned by the binding.
+	Label string `json:"label,omitempty"`
+}
+
+func (r *bindingRecord) addEnv(container, name string) {
+	if r.ownsEnv(container, name) {
+		return
+	}
+	if r.Env == nil {
+		r.Env = map[string][]string{}
+	}
+	r.Env[container] = append(r.Env[container], name)
+}
+
+func (r *bindingRecord) ownsEnv(container, name string) bool {
+	return contains(r.Env[container], name)
+}
+
+func (r *bindingRecord) addVolumeMount(container, mountPath string) {
+	if r.ownsVolumeMount(container, mountPath) {
+		return
+	}
+	if r.VolumeMounts == nil {
+		r.VolumeMounts = map[string][]string{}
+	}
+	r.VolumeMounts[container] = append(r.VolumeMounts[container], mountPath)
+}
+
+func (r *bindingRecord) ownsVolumeMount(container, mountPath string) bool {
+	return contains(r.VolumeMounts[container], mountPath)
+}
+
+// ownedEnv returns true if any of the records own the environment variable within the
+// container.
+func ownedEnv(records map[string]bindingRecord, container, name string) bool {
+	for _, r := range records {
+		if r.ownsEnv(container, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeOwner returns the name of the binding owning the volume, or an empty string if the
+// volume is not owned by a binding.
+func volumeOwner(records map[string]bindingRecord, volume string) string {
+	for name, r := range records {
+		if r.Volume == volume {
+			return name
+		}
+	}
+	return ""
+}
+
+// volumeMountOwner returns the name of the binding owning the volume mount within the
+// container, or an empty string if the volume mount is not owned by a binding.
+func volumeMountOwner(records map[string]bindingRecord, container, mountPath string) string {
+	for name, r := range records {
+		if r.ownsVolumeMount(container, mountPath) {
+			return name
+		}
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checksumAnnotation returns the annotation key holding the checksum of the named binding's
+// secret.
+func checksumAnnotation(name string) string {
+	return qualifiedKey(ChecksumAnnotationPrefix, name)
+}
+
+func typeAnnotation(name string) string {
+	return qualifiedKey(TypeAnnotationPrefix, name)
+}
+
+func providerAnnotation(name string) string {
+	return qualifiedKey(ProviderAnnotationPrefix, name)
+}
+
+// qualifiedKey joins the prefix and binding name into an annotation or label key. Binding names
+// that are not valid as the name segment of a key are hashed.
+func qualifiedKey(prefix, name string) string {
+	if !strings.Contains(name, "/") && len(validation.IsQualifiedName(name)) == 0 {
+		return prefix + name
+	}
+	sum := sha256.Sum256([]byte(name))
+	return prefix + hex.EncodeToString(sum[:])[:validation.DNS1123LabelMaxLength]
+}
+
+// readRecords returns the binding records from the annotations. Missing annotations are treated
+// as no bindings.
+func readRecords(annotations map[string]string) (map[string]bindingRecord, error) {
+	records := map[string]bindingRecord{}
+	raw, ok := annotations[BindingsAnnotation]
+	if !ok || raw == "" {
+		return records, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &records); err != nil {
+		return nil, fmt.Errorf("malformed %s annotation: %w", BindingsAnnotation, err)
+	}
+	return records, nil
+}
+
+// writeRecords stores the binding records into the annotations. The annotation is removed once
+// no bindings remain.
+func writeRecords(annotations map[string]string, records map[string]bindingRecord) error {
+	if len(records) == 0 {
+		delete(annotations, BindingsAnnotation)
+		return nil
+	}
+	// map keys are marshaled in sorted order, keeping the annotation stable
+	raw, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	annotations[BindingsAnnotation] = string(raw)
+	return nil
+}
@@ -0,0 +1,95 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChecksumAnnotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		binding  string
+		expected string
+	}{
+		{
+			name:     "valid name",
+			binding:  "db",
+			expected: "checksum.meta-binding.scothis.github.io/db",
+		},
+		{
+			name:     "nested name",
+			binding:  "db/primary",
+			expected: "checksum.meta-binding.scothis.github.io/d1ccd2540ed0ef3eee7b21fd15025229b420c021451c2635fe72215add56e9b",
+		},
+		{
+			name:     "empty name",
+			binding:  "",
+			expected: "checksum.meta-binding.scothis.github.io/e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b85",
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := checksumAnnotation(c.binding)
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("checksumAnnotation() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    map[string]bindingRecord
+		expectedErr bool
+	}{
+		{
+			name:        "no annotations",
+			annotations: map[string]string{},
+			expected:    map[string]bindingRecord{},
+		},
+		{
+			name: "records",
+			annotations: map[string]string{
+				BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+			},
+			expected: map[string]bindingRecord{
+				"db": {
+					Volume: "binding-db",
+					Env: map[string][]string{
+						"hello": {"SERVICE_BINDING_ROOT"},
+					},
+					VolumeMounts: map[string][]string{
+						"hello": {"/bindings/db"},
+					},
+				},
+			},
+		},
+		{
+			name: "malformed",
+			annotations: map[string]string{
+				BindingsAnnotation: `[]`,
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := readRecords(c.annotations)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("readRecords() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("readRecords() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package binding
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Reconcile converges the bindings applied to the object with the desired bindings. Applied
+// bindings are discovered from the ownership markers Bind records in the pod template
+// annotations. Bindings that are no longer desired are removed, while desired bindings are
+// added or updated in place. Reconciling an object that is already up to date leaves it
+// unchanged, and the returned result reports no changes.
+func Reconcile(obj runtime.Object, m *PodMapping, desired []Binding, opts BindOptions) (BindResult, error) {
+	opts.Default()
+	mpt, err := m.ToMeta(obj)
+	if err != nil {
+		return BindResult{}, err
+	}
+	before := mpt.DeepCopy()
+	records, err := readRecords(mpt.Annotations)
+	if err != nil {
+		return BindResult{}, err
+	}
+
+	errs := []error{}
+	volumes := map[string]string{}
+	for i := range desired {
+		b := &desired[i]
+		if err := b.validateName(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := volumes[b.Name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate binding name %q", b.Name))
+			continue
+		}
+		volumes[b.Name] = ""
+		if b.Secret.Name != "" {
+			volumes[b.Name] = opts.VolumeName(b)
+		}
+	}
+	if len(errs) != 0 {
+		return BindResult{}, utilerrors.NewAggregate(errs)
+	}
+
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// a binding whose volume is renamed must be removed, otherwise the old volume is
+		// orphaned; other changes are updated in place
+		if volume, ok := volumes[name]; ok && volume == records[name].Volume {
+			continue
+		}
+		if err := unbind(&mpt, name); err != nil {
+			return BindResult{}, fmt.Errorf("binding %q: %w", name, err)
+		}
+	}
+
+	skips, err := bindAll(&mpt, desired, opts)
+	if err != nil {
+		return BindResult{}, err
+	}
+
+	if err := m.FromMeta(obj, mpt); err != nil {
+		return BindResult{}, err
+	}
+	return newBindResult(before, &mpt, skips), nil
+}
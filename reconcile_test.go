@@ -0,0 +1,1017 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name        string
+		desired     []Binding
+		options     BindOptions
+		mapping     PodMapping
+		seed        runtime.Object
+		expected    runtime.Object
+		expectedErr bool
+	}{
+		{
+			name: "add missing binding",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "add to existing bindings",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+				Binding{
+					Name: "cache",
+					Secret: corev1.LocalObjectReference{
+						Name: "cache-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"cache":{"volume":"binding-cache","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/cache"]}},"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+										{
+											Name:      "binding-cache",
+											MountPath: "/bindings/cache",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+								{
+									Name: "binding-cache",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "cache-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unchanged",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "remove undesired binding",
+			desired: []Binding{},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env:  []corev1.EnvVar{},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "replace binding",
+			desired: []Binding{
+				Binding{
+					Name: "cache",
+					Secret: corev1.LocalObjectReference{
+						Name: "cache-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"cache":{"volume":"binding-cache","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/cache"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-cache",
+											MountPath: "/bindings/cache",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-cache",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "cache-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "update changed binding",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "rotated-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "rotated-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "renamed volume",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+			},
+			options: BindOptions{
+				VolumeName: func(b *Binding) string {
+					return b.Name
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate desired bindings",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "other-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "path-like desired binding name",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+				Binding{
+					Name: "./db",
+					Secret: corev1.LocalObjectReference{
+						Name: "other-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "SERVICE_BINDING_ROOT",
+											Value: "/bindings",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config",
+											MountPath: "/config",
+										},
+										{
+											Name:      "binding-db",
+											MountPath: "/bindings/db",
+											ReadOnly:  true,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: "config",
+											},
+										},
+									},
+								},
+								{
+									Name: "binding-db",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{
+											SecretName: "db-secret",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "malformed annotation",
+			desired: []Binding{
+				Binding{
+					Name: "db",
+					Secret: corev1.LocalObjectReference{
+						Name: "db-secret",
+					},
+				},
+			},
+			mapping: PodMapping{},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Annotations: map[string]string{
+								BindingsAnnotation: `{`,
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.seed.DeepCopyObject()
+			m := &c.mapping
+			m.Default()
+			_, err := Reconcile(actual, m, c.desired, c.options)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("Reconcile() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Reconcile() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
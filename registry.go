@@ -0,0 +1,107 @@
+package binding
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Registry holds the PodMapping for each known kind of workload. Mappings are keyed by group
+// and kind as the location of the pod template does not vary between versions.
+type Registry map[schema.GroupKind]PodMapping
+
+// DefaultRegistry returns mappings for the well known Kubernetes workloads. Kinds exposing a pod
+// template at `.spec.template` use the default PodMapping.
+func DefaultRegistry() Registry {
+	return Registry{
+		{Group: "", Kind: "Pod"}: {
+			Annotations: "/metadata/annotations",
+			Labels:      "/metadata/labels",
+			Containers: []ContainerMapping{
+				{
+					Path: ".spec.initContainers[*]",
+					Name: "/name",
+					Role: RoleInit,
+				},
+				{
+					Path: ".spec.containers[*]",
+					Name: "/name",
+					Role: RoleApp,
+				},
+				{
+					Path: ".spec.ephemeralContainers[*]",
+					Name: "/name",
+					Role: RoleEphemeral,
+				},
+			},
+			Volumes: "/spec/volumes",
+		},
+		{Group: "", Kind: "PodTemplate"}: {
+			Annotations: "/template/metadata/annotations",
+			Labels:      "/template/metadata/labels",
+			Containers: []ContainerMapping{
+				{
+					Path: ".template.spec.initContainers[*]",
+					Name: "/name",
+					Role: RoleInit,
+				},
+				{
+					Path: ".template.spec.containers[*]",
+					Name: "/name",
+					Role: RoleApp,
+				},
+				{
+					Path: ".template.spec.ephemeralContainers[*]",
+					Name: "/name",
+					Role: RoleEphemeral,
+				},
+			},
+			Volumes: "/template/spec/volumes",
+		},
+		{Group: "", Kind: "ReplicationController"}: {},
+		{Group: "apps", Kind: "DaemonSet"}:         {},
+		{Group: "apps", Kind: "Deployment"}:        {},
+		{Group: "apps", Kind: "ReplicaSet"}:        {},
+		{Group: "apps", Kind: "StatefulSet"}:       {},
+		{Group: "batch", Kind: "Job"}:              {},
+		{Group: "batch", Kind: "CronJob"}: {
+			Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+			Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+			Containers: []ContainerMapping{
+				{
+					Path: ".spec.jobTemplate.spec.template.spec.initContainers[*]",
+					Name: "/name",
+					Role: RoleInit,
+				},
+				{
+					Path: ".spec.jobTemplate.spec.template.spec.containers[*]",
+					Name: "/name",
+					Role: RoleApp,
+				},
+				{
+					Path: ".spec.jobTemplate.spec.template.spec.ephemeralContainers[*]",
+					Name: "/name",
+					Role: RoleEphemeral,
+				},
+			},
+			Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+		},
+		{Group: "serving.knative.dev", Kind: "Service"}:       {},
+		{Group: "serving.knative.dev", Kind: "Configuration"}: {},
+	}
+}
+
+// Lookup returns a defaulted copy of the mapping for the kind, or false if the kind is not
+// registered.
+func (r Registry) Lookup(gvk schema.GroupVersionKind) (*PodMapping, bool) {
+	m, ok := r[gvk.GroupKind()]
+	if !ok {
+		return nil, false
+	}
+	mapping := &PodMapping{
+		Annotations: m.Annotations,
+		Labels:      m.Labels,
+		Containers:  append([]ContainerMapping{}, m.Containers...),
+		Volumes:     m.Volumes,
+	}
+	mapping.Default()
+	return mapping, true
+}
@@ -0,0 +1,296 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRegistry_Lookup(t *testing.T) {
+	tests := []struct {
+		name       string
+		gvk        schema.GroupVersionKind
+		expected   *PodMapping
+		expectedOk bool
+	}{
+		{
+			name: "pod template at spec.template",
+			gvk:  schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			expected: &PodMapping{
+				Annotations: "/spec/template/metadata/annotations",
+				Labels:      "/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path:         ".spec.template.spec.initContainers[*]",
+						Name:         "/name",
+						Env:          "/env",
+						VolumeMounts: "/volumeMounts",
+						Role:         RoleInit,
+					},
+					{
+						Path:         ".spec.template.spec.containers[*]",
+						Name:         "/name",
+						Env:          "/env",
+						VolumeMounts: "/volumeMounts",
+						Role:         RoleApp,
+					},
+					{
+						Path:         ".spec.template.spec.ephemeralContainers[*]",
+						Name:         "/name",
+						Env:          "/env",
+						VolumeMounts: "/volumeMounts",
+						Role:         RoleEphemeral,
+					},
+				},
+				Volumes: "/spec/template/spec/volumes",
+			},
+			expectedOk: true,
+		},
+		{
+			name: "any version",
+			gvk:  schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"},
+			expected: &PodMapping{
+				Annotations: "/spec/jobTemplate/spec/template/metadata/annotations",
+				Labels:      "/spec/jobTemplate/spec/template/metadata/labels",
+				Containers: []ContainerMapping{
+					{
+						Path:         ".spec.jobTemplate.spec.template.spec.initContainers[*]",
+						Name:         "/name",
+						Env:          "/env",
+						VolumeMounts: "/volumeMounts",
+						Role:         RoleInit,
+					},
+					{
+						Path:         ".spec.jobTemplate.spec.template.spec.containers[*]",
+						Name:         "/name",
+						Env:          "/env",
+						VolumeMounts: "/volumeMounts",
+						Role:         RoleApp,
+					},
+					{
+						Path:         ".spec.jobTemplate.spec.template.spec.ephemeralContainers[*]",
+						Name:         "/name",
+						Env:          "/env",
+						VolumeMounts: "/volumeMounts",
+						Role:         RoleEphemeral,
+					},
+				},
+				Volumes: "/spec/jobTemplate/spec/template/spec/volumes",
+			},
+			expectedOk: true,
+		},
+		{
+			name:       "unknown kind",
+			gvk:        schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"},
+			expectedOk: false,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual, ok := DefaultRegistry().Lookup(c.gvk)
+			if c.expectedOk != ok {
+				t.Errorf("Lookup() expected ok %v, got %v", c.expectedOk, ok)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Lookup() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_Lookup_Copy(t *testing.T) {
+	r := DefaultRegistry()
+	gvk := corev1.SchemeGroupVersion.WithKind("Pod")
+	m, _ := r.Lookup(gvk)
+	m.Containers[0].Path = ".mutated"
+	if actual, _ := r.Lookup(gvk); actual.Containers[0].Path == ".mutated" {
+		t.Errorf("Lookup() returned a mapping sharing state with the registry")
+	}
+}
+
+func TestRegistry_Bind(t *testing.T) {
+	tests := []struct {
+		name     string
+		binding  Binding
+		seed     runtime.Object
+		expected runtime.Object
+	}{
+		{
+			name: "ephemeral containers are skipped",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+			},
+			seed: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "hello",
+						},
+					},
+					EphemeralContainers: []corev1.EphemeralContainer{
+						{
+							EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+								Name: "debugger",
+							},
+						},
+					},
+				},
+			},
+			expected: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "hello",
+							Env: []corev1.EnvVar{
+								{
+									Name:  "SERVICE_BINDING_ROOT",
+									Value: "/bindings",
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "binding-db",
+									MountPath: "/bindings/db",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					EphemeralContainers: []corev1.EphemeralContainer{
+						{
+							EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+								Name:         "debugger",
+								Env:          []corev1.EnvVar{},
+								VolumeMounts: []corev1.VolumeMount{},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "binding-db",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "db-secret",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ephemeral containers opt in",
+			binding: Binding{
+				Name: "db",
+				Secret: corev1.LocalObjectReference{
+					Name: "db-secret",
+				},
+				Selection: &ContainerSelection{
+					Roles: []string{RoleApp, RoleEphemeral},
+				},
+			},
+			seed: &corev1.Pod{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "hello",
+						},
+					},
+					EphemeralContainers: []corev1.EphemeralContainer{
+						{
+							EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+								Name: "debugger",
+							},
+						},
+					},
+				},
+			},
+			expected: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"debugger":["SERVICE_BINDING_ROOT"],"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"debugger":["/bindings/db"],"hello":["/bindings/db"]}}}`,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "hello",
+							Env: []corev1.EnvVar{
+								{
+									Name:  "SERVICE_BINDING_ROOT",
+									Value: "/bindings",
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "binding-db",
+									MountPath: "/bindings/db",
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					EphemeralContainers: []corev1.EphemeralContainer{
+						{
+							EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+								Name: "debugger",
+								Env: []corev1.EnvVar{
+									{
+										Name:  "SERVICE_BINDING_ROOT",
+										Value: "/bindings",
+									},
+								},
+								VolumeMounts: []corev1.VolumeMount{
+									{
+										Name:      "binding-db",
+										MountPath: "/bindings/db",
+										ReadOnly:  true,
+									},
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "binding-db",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "db-secret",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.seed.DeepCopyObject()
+			m, ok := DefaultRegistry().Lookup(corev1.SchemeGroupVersion.WithKind("Pod"))
+			if !ok {
+				t.Fatalf("Lookup() expected Pod to be registered")
+			}
+			if _, err := c.binding.Bind(actual, m, BindOptions{}); err != nil {
+				t.Errorf("Bind() unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Bind() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package binding
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// ContainerStatus summarizes the effect of binding on a container.
+type ContainerStatus string
+
+const (
+	// ContainerBound is a container modified by the binding.
+	ContainerBound ContainerStatus = "Bound"
+	// ContainerAlreadyBound is a container selected by the binding that was already up to date.
+	ContainerAlreadyBound ContainerStatus = "AlreadyBound"
+	// ContainerSkipped is a container the binding was not applied to, either because it was not
+	// selected or because of a conflict skipped by the ConflictPolicy.
+	ContainerSkipped ContainerStatus = "Skipped"
+)
+
+// BindResult describes the changes made to an object by binding.
+type BindResult struct {
+	// Changed is true if the pod template was modified. An unchanged object does not need to be
+	// updated.
+	Changed bool
+	// Containers has an entry for each container discovered by the PodMapping, in the order
+	// they were discovered.
+	Containers []ContainerResult
+	// Volumes is the names of the pod template volumes that changed.
+	Volumes Changes
+}
+
+type ContainerResult struct {
+	// Name of the container.
+	Name string
+	// Location is a JSON Pointer to the container within the object.
+	Location string
+	// Role is the label of the ContainerMapping that discovered the container.
+	Role string
+	// Status summarizes the effect of binding on the container.
+	Status ContainerStatus
+	// Reason describes why the container was skipped.
+	// +optional
+	Reason string
+	// Env is the names of the environment variables that changed.
+	Env Changes
+	// VolumeMounts is the paths of the volume mounts that changed.
+	VolumeMounts Changes
+}
+
+// Changes lists the keys of the entries added, updated and removed, in sorted order.
+type Changes struct {
+	// +optional
+	Added []string
+	// +optional
+	Updated []string
+	// +optional
+	Removed []string
+}
+
+// IsEmpty returns true if nothing changed.
+func (c Changes) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// newBindResult compares the MetaPodTemplate before and after binding. Skips holds the reasons
+// each container was skipped, indexed by container; containers without a reason were selected.
+// Binding never adds or removes containers, so containers are compared by index.
+func newBindResult(before, after *MetaPodTemplate, skips []string) BindResult {
+	result := BindResult{
+		Changed:    !equality.Semantic.DeepEqual(before, after),
+		Containers: make([]ContainerResult, len(after.Containers)),
+	}
+
+	beforeVolumes := map[string]interface{}{}
+	for i := range before.Volumes {
+		beforeVolumes[before.Volumes[i].Name] = before.Volumes[i]
+	}
+	afterVolumes := map[string]interface{}{}
+	for i := range after.Volumes {
+		afterVolumes[after.Volumes[i].Name] = after.Volumes[i]
+	}
+	result.Volumes = diffEntries(beforeVolumes, afterVolumes)
+
+	for i := range after.Containers {
+		c := &after.Containers[i]
+		cr := ContainerResult{
+			Name:     c.Name,
+			Location: c.Location,
+			Role:     c.Role,
+		}
+		if i < len(before.Containers) {
+			cr.Env = diffEnv(before.Containers[i].Env, c.Env)
+			cr.VolumeMounts = diffVolumeMounts(before.Containers[i].VolumeMounts, c.VolumeMounts)
+		}
+		switch {
+		case i < len(skips) && skips[i] != "":
+			cr.Status = ContainerSkipped
+			cr.Reason = skips[i]
+		case cr.Env.IsEmpty() && cr.VolumeMounts.IsEmpty():
+			cr.Status = ContainerAlreadyBound
+		default:
+			cr.Status = ContainerBound
+		}
+		result.Containers[i] = cr
+	}
+
+	return result
+}
+
+func diffEnv(before, after []corev1.EnvVar) Changes {
+	b := map[string]interface{}{}
+	for i := range before {
+		b[before[i].Name] = before[i]
+	}
+	a := map[string]interface{}{}
+	for i := range after {
+		a[after[i].Name] = after[i]
+	}
+	return diffEntries(b, a)
+}
+
+func diffVolumeMounts(before, after []corev1.VolumeMount) Changes {
+	b := map[string]interface{}{}
+	for i := range before {
+		b[before[i].MountPath] = before[i]
+	}
+	a := map[string]interface{}{}
+	for i := range after {
+		a[after[i].MountPath] = after[i]
+	}
+	return diffEntries(b, a)
+}
+
+// diffEntries compares entries by key.
+func diffEntries(before, after map[string]interface{}) Changes {
+	changes := Changes{}
+	for key, a := range after {
+		b, ok := before[key]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, key)
+		case !equality.Semantic.DeepEqual(b, a):
+			changes.Updated = append(changes.Updated, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changes.Removed = append(changes.Removed, key)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Updated)
+	sort.Strings(changes.Removed)
+	return changes
+}
+
+// mergeSkips combines the reasons the containers were skipped by each of several bindings. A
+// container is only skipped if every binding skipped it.
+func mergeSkips(skips [][]string, containers int) []string {
+	merged := make([]string, containers)
+	for i := range merged {
+		if len(skips) == 0 {
+			merged[i] = "no bindings"
+			continue
+		}
+		reasons := []string{}
+		for _, s := range skips {
+			if s[i] == "" {
+				reasons = nil
+				break
+			}
+			reasons = append(reasons, s[i])
+		}
+		merged[i] = strings.Join(reasons, "; ")
+	}
+	return merged
+}
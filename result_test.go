@@ -0,0 +1,324 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestBindResult(t *testing.T) {
+	readWrite := false
+	db := Binding{
+		Name: "db",
+		Secret: corev1.LocalObjectReference{
+			Name: "db-secret",
+		},
+	}
+	cache := Binding{
+		Name: "cache",
+		Secret: corev1.LocalObjectReference{
+			Name: "cache-secret",
+		},
+		Containers: []string{"worker"},
+	}
+	bound := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						BindingsAnnotation: `{"db":{"volume":"binding-db","env":{"hello":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"hello":["/bindings/db"]}}}`,
+					},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "hello",
+							Env: []corev1.EnvVar{
+								{
+									Name:  "SERVICE_BINDING_ROOT",
+									Value: "/bindings",
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "binding-db",
+									MountPath: "/bindings/db",
+									ReadOnly:  true,
+								},
+							},
+						},
+						{
+							Name: "istio-proxy",
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "binding-db",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: "db-secret",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		bind     func(obj runtime.Object, m *PodMapping) (BindResult, error)
+		seed     runtime.Object
+		expected BindResult
+	}{
+		{
+			name: "bound",
+			bind: func(obj runtime.Object, m *PodMapping) (BindResult, error) {
+				return db.Bind(obj, m, BindOptions{})
+			},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: BindResult{
+				Changed: true,
+				Containers: []ContainerResult{
+					{
+						Name:     "hello",
+						Location: "/spec/template/spec/containers/0",
+						Role:     RoleApp,
+						Status:   ContainerBound,
+						Env: Changes{
+							Added: []string{"SERVICE_BINDING_ROOT"},
+						},
+						VolumeMounts: Changes{
+							Added: []string{"/bindings/db"},
+						},
+					},
+					{
+						Name:     "istio-proxy",
+						Location: "/spec/template/spec/containers/1",
+						Role:     RoleApp,
+						Status:   ContainerSkipped,
+						Reason:   `binding "db": container not selected`,
+					},
+				},
+				Volumes: Changes{
+					Added: []string{"binding-db"},
+				},
+			},
+		},
+		{
+			name: "already bound",
+			bind: func(obj runtime.Object, m *PodMapping) (BindResult, error) {
+				return db.Bind(obj, m, BindOptions{})
+			},
+			seed: bound,
+			expected: BindResult{
+				Changed: false,
+				Containers: []ContainerResult{
+					{
+						Name:     "hello",
+						Location: "/spec/template/spec/containers/0",
+						Role:     RoleApp,
+						Status:   ContainerAlreadyBound,
+					},
+					{
+						Name:     "istio-proxy",
+						Location: "/spec/template/spec/containers/1",
+						Role:     RoleApp,
+						Status:   ContainerSkipped,
+						Reason:   `binding "db": container not selected`,
+					},
+				},
+			},
+		},
+		{
+			name: "updated",
+			bind: func(obj runtime.Object, m *PodMapping) (BindResult, error) {
+				return db.Bind(obj, m, BindOptions{ReadOnly: &readWrite})
+			},
+			seed: bound,
+			expected: BindResult{
+				Changed: true,
+				Containers: []ContainerResult{
+					{
+						Name:     "hello",
+						Location: "/spec/template/spec/containers/0",
+						Role:     RoleApp,
+						Status:   ContainerBound,
+						VolumeMounts: Changes{
+							Updated: []string{"/bindings/db"},
+						},
+					},
+					{
+						Name:     "istio-proxy",
+						Location: "/spec/template/spec/containers/1",
+						Role:     RoleApp,
+						Status:   ContainerSkipped,
+						Reason:   `binding "db": container not selected`,
+					},
+				},
+			},
+		},
+		{
+			name: "skipped by conflict",
+			bind: func(obj runtime.Object, m *PodMapping) (BindResult, error) {
+				return db.Bind(obj, m, BindOptions{ConflictPolicy: ConflictPolicySkip})
+			},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "hello",
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/bindings",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: BindResult{
+				Changed: true,
+				Containers: []ContainerResult{
+					{
+						Name:     "hello",
+						Location: "/spec/template/spec/containers/0",
+						Role:     RoleApp,
+						Status:   ContainerSkipped,
+						Reason:   `binding "db": container "hello": volume mount at "/bindings" owned by user: shadowed by "/bindings/db"`,
+						Env: Changes{
+							Added: []string{"SERVICE_BINDING_ROOT"},
+						},
+					},
+				},
+				Volumes: Changes{
+					Added: []string{"binding-db"},
+				},
+			},
+		},
+		{
+			name: "bind all",
+			bind: func(obj runtime.Object, m *PodMapping) (BindResult, error) {
+				return BindAll(obj, m, []Binding{db, cache}, BindOptions{})
+			},
+			seed: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name: "worker",
+								},
+								{
+									Name: "istio-proxy",
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: BindResult{
+				Changed: true,
+				Containers: []ContainerResult{
+					{
+						Name:     "worker",
+						Location: "/spec/template/spec/containers/0",
+						Role:     RoleApp,
+						Status:   ContainerBound,
+						Env: Changes{
+							Added: []string{"SERVICE_BINDING_ROOT"},
+						},
+						VolumeMounts: Changes{
+							Added: []string{"/bindings/cache", "/bindings/db"},
+						},
+					},
+					{
+						Name:     "istio-proxy",
+						Location: "/spec/template/spec/containers/1",
+						Role:     RoleApp,
+						Status:   ContainerSkipped,
+						Reason:   `binding "cache": container not selected; binding "db": container not selected`,
+					},
+				},
+				Volumes: Changes{
+					Added: []string{"binding-cache", "binding-db"},
+				},
+			},
+		},
+		{
+			name: "reconcile removes",
+			bind: func(obj runtime.Object, m *PodMapping) (BindResult, error) {
+				return Reconcile(obj, m, []Binding{}, BindOptions{})
+			},
+			seed: bound,
+			expected: BindResult{
+				Changed: true,
+				Containers: []ContainerResult{
+					{
+						Name:     "hello",
+						Location: "/spec/template/spec/containers/0",
+						Role:     RoleApp,
+						Status:   ContainerSkipped,
+						Reason:   "no bindings",
+						Env: Changes{
+							Removed: []string{"SERVICE_BINDING_ROOT"},
+						},
+						VolumeMounts: Changes{
+							Removed: []string{"/bindings/db"},
+						},
+					},
+					{
+						Name:     "istio-proxy",
+						Location: "/spec/template/spec/containers/1",
+						Role:     RoleApp,
+						Status:   ContainerSkipped,
+						Reason:   "no bindings",
+					},
+				},
+				Volumes: Changes{
+					Removed: []string{"binding-db"},
+				},
+			},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.seed.DeepCopyObject()
+			m := &PodMapping{}
+			m.Default()
+			result, err := c.bind(actual, m)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, result); diff != "" {
+				t.Errorf("BindResult (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package binding
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// JSONSchemaProps is the subset of a CustomResourceDefinition's OpenAPI v3 schema needed to
+// validate a PodMapping.
+type JSONSchemaProps struct {
+	Type                 string                     `json:"type,omitempty"`
+	Properties           map[string]JSONSchemaProps `json:"properties,omitempty"`
+	Items                *JSONSchemaProps           `json:"items,omitempty"`
+	AdditionalProperties *JSONSchemaPropsOrBool     `json:"additionalProperties,omitempty"`
+
+	XPreserveUnknownFields *bool `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	XEmbeddedResource      bool  `json:"x-kubernetes-embedded-resource,omitempty"`
+	XIntOrString           bool  `json:"x-kubernetes-int-or-string,omitempty"`
+}
+
+// JSONSchemaPropsOrBool is either a schema, or a boolean allowing any value.
+type JSONSchemaPropsOrBool struct {
+	Allows bool
+	Schema *JSONSchemaProps
+}
+
+func (s *JSONSchemaPropsOrBool) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.Allows); err == nil {
+		s.Schema = nil
+		return nil
+	}
+	s.Allows = true
+	s.Schema = &JSONSchemaProps{}
+	return json.Unmarshal(data, s.Schema)
+}
+
+// anySchema accepts any value.
+var anySchema = &JSONSchemaProps{}
+
+// isAny returns true if the schema places no constraint on the value.
+func (s *JSONSchemaProps) isAny() bool {
+	if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields && len(s.Properties) == 0 {
+		return true
+	}
+	return s.Type == "" && !s.XIntOrString && len(s.Properties) == 0 && s.Items == nil && s.AdditionalProperties == nil
+}
+
+// child returns the schema of the object's field, or nil if the field is not defined and would
+// be pruned. The metadata of the root object and of embedded resources is always defined.
+func (s *JSONSchemaProps) child(key string, root bool) *JSONSchemaProps {
+	if s.isAny() {
+		return anySchema
+	}
+	if key == "metadata" && (root || s.XEmbeddedResource) {
+		return objectMetaSchema
+	}
+	if p, ok := s.Properties[key]; ok {
+		return &p
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Allows {
+		if s.AdditionalProperties.Schema == nil {
+			return anySchema
+		}
+		return s.AdditionalProperties.Schema
+	}
+	if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields {
+		return anySchema
+	}
+	return nil
+}
+
+// objectMetaSchema is the schema of the fields of an ObjectMeta written by binding.
+var objectMetaSchema = &JSONSchemaProps{
+	Type: "object",
+	Properties: map[string]JSONSchemaProps{
+		"annotations": {
+			Type:                 "object",
+			AdditionalProperties: &JSONSchemaPropsOrBool{Allows: true, Schema: &JSONSchemaProps{Type: "string"}},
+		},
+		"labels": {
+			Type:                 "object",
+			AdditionalProperties: &JSONSchemaPropsOrBool{Allows: true, Schema: &JSONSchemaProps{Type: "string"}},
+		},
+	},
+	XPreserveUnknownFields: func() *bool { b := true; return &b }(),
+}
+
+// ValidateSchema checks the mapping against the OpenAPI v3 schema of a custom resource. Each
+// ContainerMapping's Path must find objects, and each pointer must resolve to a field compatible
+// with the value bound to it. Fields that are not defined by the schema are reported, as they
+// would be pruned by the API server. Paths using recursive descent or filters cannot be resolved
+// against a schema and are not checked beyond Validate. The mapping should be defaulted.
+func (m *PodMapping) ValidateSchema(schema *JSONSchemaProps) field.ErrorList {
+	if errs := m.Validate(); len(errs) != 0 {
+		return errs
+	}
+	errs := field.ErrorList{}
+
+	errs = append(errs, validateSchemaPointer(schema, "", m.Annotations, field.NewPath("annotations"), reflect.TypeOf(map[string]string{}))...)
+	if m.Labels != "" {
+		errs = append(errs, validateSchemaPointer(schema, "", m.Labels, field.NewPath("labels"), reflect.TypeOf(map[string]string{}))...)
+	}
+	for i := range m.Containers {
+		cm := &m.Containers[i]
+		fldPath := field.NewPath("containers").Index(i)
+		container, location, err := resolveSchemaPath(schema, cm.Path)
+		if err != "" {
+			errs = append(errs, field.Invalid(fldPath.Child("path"), cm.Path, err))
+			continue
+		}
+		if container == nil {
+			// not resolvable against a schema
+			continue
+		}
+		if !container.isAny() && container.Type != "object" {
+			errs = append(errs, field.Invalid(fldPath.Child("path"), cm.Path, fmt.Sprintf("expected object at %q, schema has type %q", location, container.Type)))
+			continue
+		}
+		if cm.Name != "" {
+			errs = append(errs, validateSchemaPointer(container, location, cm.Name, fldPath.Child("name"), reflect.TypeOf(""))...)
+		}
+		errs = append(errs, validateSchemaPointer(container, location, cm.Env, fldPath.Child("env"), reflect.TypeOf([]corev1.EnvVar{}))...)
+		errs = append(errs, validateSchemaPointer(container, location, cm.VolumeMounts, fldPath.Child("volumeMounts"), reflect.TypeOf([]corev1.VolumeMount{}))...)
+	}
+	errs = append(errs, validateSchemaPointer(schema, "", m.Volumes, field.NewPath("volumes"), reflect.TypeOf([]corev1.Volume{}))...)
+
+	return errs
+}
+
+// ValidateCRD checks the mapping against the schema of a version of the CustomResourceDefinition
+// encoded as YAML or JSON. If version is empty, the storage version is used. Both v1 and v1beta1
+// CustomResourceDefinitions are understood.
+func (m *PodMapping) ValidateCRD(data []byte, version string) (field.ErrorList, error) {
+	crd := &customResourceDefinition{}
+	if err := yaml.Unmarshal(data, crd); err != nil {
+		return nil, err
+	}
+	schema := crd.Spec.Validation
+	for _, v := range crd.Spec.Versions {
+		if (version == "" && v.Storage) || (version != "" && v.Name == version) {
+			if v.Schema != nil {
+				schema = v.Schema
+			}
+			version = v.Name
+			break
+		}
+	}
+	if version == "" && len(crd.Spec.Versions) == 0 {
+		version = crd.Spec.Version
+	}
+	if schema == nil || schema.OpenAPIV3Schema == nil {
+		return nil, fmt.Errorf("no openAPIV3Schema found for version %q", version)
+	}
+	return m.ValidateSchema(schema.OpenAPIV3Schema), nil
+}
+
+// customResourceDefinition is the subset of a v1 or v1beta1 CustomResourceDefinition holding
+// its schemas.
+type customResourceDefinition struct {
+	Spec struct {
+		Version  string `json:"version,omitempty"`
+		Versions []struct {
+			Name    string                    `json:"name"`
+			Storage bool                      `json:"storage"`
+			Schema  *customResourceValidation `json:"schema,omitempty"`
+		} `json:"versions,omitempty"`
+		Validation *customResourceValidation `json:"validation,omitempty"`
+	} `json:"spec"`
+}
+
+type customResourceValidation struct {
+	OpenAPIV3Schema *JSONSchemaProps `json:"openAPIV3Schema,omitempty"`
+}
+
+// resolveSchemaPath returns the schema of the values found by the JSONPath query, along with
+// their location. A nil schema without an error is returned for queries that cannot be resolved
+// against a schema.
+func resolveSchemaPath(schema *JSONSchemaProps, path string) (*JSONSchemaProps, string, string) {
+	parser, err := jsonpath.Parse("", fmt.Sprintf("{%s}", path))
+	if err != nil {
+		return nil, "", err.Error()
+	}
+	if len(parser.Root.Nodes) != 1 {
+		return nil, "", ""
+	}
+	list, ok := parser.Root.Nodes[0].(*jsonpath.ListNode)
+	if !ok {
+		return nil, "", ""
+	}
+	location := ""
+	for _, n := range list.Nodes {
+		switch t := n.(type) {
+		case *jsonpath.FieldNode:
+			if t.Value == "" {
+				continue
+			}
+			if !schema.isAny() && schema.Type != "object" {
+				return nil, "", fmt.Sprintf("expected object at %q, schema has type %q", location, schema.Type)
+			}
+			next := schema.child(t.Value, location == "")
+			location = location + "/" + escapePointer(t.Value)
+			if next == nil {
+				return nil, "", fmt.Sprintf("%q is not defined by the schema", location)
+			}
+			schema = next
+		case *jsonpath.ArrayNode:
+			if schema.isAny() {
+				location = location + "/*"
+				continue
+			}
+			if schema.Type != "array" {
+				return nil, "", fmt.Sprintf("expected array at %q, schema has type %q", location, schema.Type)
+			}
+			location = location + "/*"
+			schema = schema.Items
+			if schema == nil {
+				schema = anySchema
+			}
+		default:
+			return nil, "", ""
+		}
+	}
+	return schema, location, ""
+}
+
+// validateSchemaPointer checks the pointer, relative to the schema at location, resolves to a
+// field compatible with the type.
+func validateSchemaPointer(schema *JSONSchemaProps, location, ptr string, fldPath *field.Path, t reflect.Type) field.ErrorList {
+	errs := field.ErrorList{}
+	for _, key := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		if !schema.isAny() && schema.Type != "object" {
+			return append(errs, field.Invalid(fldPath, ptr, fmt.Sprintf("expected object at %q, schema has type %q", location, schema.Type)))
+		}
+		next := schema.child(key, location == "")
+		location = location + "/" + escapePointer(key)
+		if next == nil {
+			return append(errs, field.Invalid(fldPath, ptr, fmt.Sprintf("%q is not defined by the schema", location)))
+		}
+		schema = next
+	}
+	if problem := schemaCompatible(schema, t, location); problem != "" {
+		errs = append(errs, field.Invalid(fldPath, ptr, problem))
+	}
+	return errs
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// schemaCompatible describes why values described by the schema at location cannot be decoded
+// into the type, or returns an empty string if they are compatible. Only the fields defined by
+// both are compared.
+func schemaCompatible(schema *JSONSchemaProps, t reflect.Type, location string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if schema == nil || schema.isAny() || reflect.PtrTo(t).Implements(jsonUnmarshaler) {
+		// custom decoding, such as resource.Quantity and intstr.IntOrString
+		return ""
+	}
+	expected := ""
+	switch t.Kind() {
+	case reflect.String:
+		expected = "string"
+	case reflect.Bool:
+		expected = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		expected = "integer"
+	case reflect.Float32, reflect.Float64:
+		expected = "number"
+	case reflect.Slice:
+		expected = "array"
+		if t.Elem().Kind() == reflect.Uint8 {
+			// base64 encoded
+			expected = "string"
+		}
+	case reflect.Map, reflect.Struct:
+		expected = "object"
+	case reflect.Interface:
+		return ""
+	}
+	actual := schema.Type
+	if schema.XIntOrString {
+		actual = "integer or string"
+	}
+	if actual != expected && !(expected == "number" && actual == "integer") {
+		return fmt.Sprintf("expected %s at %q, schema has type %q", t, location, actual)
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		if expected == "array" && schema.Items != nil {
+			return schemaCompatible(schema.Items, t.Elem(), location+"/*")
+		}
+	case reflect.Map:
+		for _, key := range propertyNames(schema) {
+			p := schema.Properties[key]
+			if problem := schemaCompatible(&p, t.Elem(), location+"/"+escapePointer(key)); problem != "" {
+				return problem
+			}
+		}
+		if schema.AdditionalProperties != nil {
+			return schemaCompatible(schema.AdditionalProperties.Schema, t.Elem(), location+"/*")
+		}
+	case reflect.Struct:
+		fields := jsonFields(t)
+		for _, key := range propertyNames(schema) {
+			ft, ok := fields[key]
+			if !ok {
+				continue
+			}
+			p := schema.Properties[key]
+			if problem := schemaCompatible(&p, ft, location+"/"+escapePointer(key)); problem != "" {
+				return problem
+			}
+		}
+	}
+	return ""
+}
+
+// propertyNames returns the names of the schema's properties in sorted order, so that the same
+// problem is reported each time.
+func propertyNames(schema *JSONSchemaProps) []string {
+	keys := make([]string, 0, len(schema.Properties))
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonFields returns the type of each field of the struct keyed by its JSON name, including the
+// fields of inlined structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
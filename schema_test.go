@@ -0,0 +1,323 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestPodMapping_ValidateCRD(t *testing.T) {
+	mapping := PodMapping{
+		Annotations: "/spec/template/metadata/annotations",
+		Labels:      "/spec/template/metadata/labels",
+		Containers: []ContainerMapping{
+			{
+				Path: ".spec.template.spec.containers[*]",
+				Name: "/name",
+			},
+		},
+		Volumes: "/spec/template/spec/volumes",
+	}
+
+	tests := []struct {
+		name        string
+		mapping     PodMapping
+		crd         string
+		version     string
+		expected    field.ErrorList
+		expectedErr bool
+	}{
+		{
+			name:    "preserved unknown fields",
+			mapping: mapping,
+			crd: `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  versions:
+  - name: v1
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              template:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+`,
+			expected: field.ErrorList{},
+		},
+		{
+			name:    "embedded resource",
+			mapping: mapping,
+			crd: `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  versions:
+  - name: v1
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              template:
+                type: object
+                x-kubernetes-embedded-resource: true
+                properties:
+                  spec:
+                    type: object
+                    properties:
+                      containers:
+                        type: array
+                        items:
+                          type: object
+                          properties:
+                            name:
+                              type: string
+                            env:
+                              type: array
+                              items:
+                                type: object
+                                properties:
+                                  name:
+                                    type: string
+                                  value:
+                                    type: string
+                                  valueFrom:
+                                    type: object
+                                    x-kubernetes-preserve-unknown-fields: true
+                            volumeMounts:
+                              type: array
+                              items:
+                                type: object
+                                x-kubernetes-preserve-unknown-fields: true
+                      volumes:
+                        type: array
+                        items:
+                          type: object
+                          properties:
+                            name:
+                              type: string
+                            secret:
+                              type: object
+                              properties:
+                                defaultMode:
+                                  type: integer
+                                secretName:
+                                  type: string
+`,
+			expected: field.ErrorList{},
+		},
+		{
+			name:    "incompatible schema",
+			mapping: mapping,
+			crd: `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  versions:
+  - name: v1alpha1
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+  - name: v1
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              template:
+                type: object
+                properties:
+                  metadata:
+                    type: object
+                  spec:
+                    type: object
+                    properties:
+                      containers:
+                        type: array
+                        items:
+                          type: object
+                          properties:
+                            name:
+                              type: integer
+                            env:
+                              type: object
+                      volumes:
+                        type: array
+                        items:
+                          type: object
+                          properties:
+                            secret:
+                              type: object
+                              properties:
+                                defaultMode:
+                                  type: string
+`,
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("annotations"), "/spec/template/metadata/annotations", `"/spec/template/metadata/annotations" is not defined by the schema`),
+				field.Invalid(field.NewPath("labels"), "/spec/template/metadata/labels", `"/spec/template/metadata/labels" is not defined by the schema`),
+				field.Invalid(field.NewPath("containers").Index(0).Child("name"), "/name", `expected string at "/spec/template/spec/containers/*/name", schema has type "integer"`),
+				field.Invalid(field.NewPath("containers").Index(0).Child("env"), "/env", `expected []v1.EnvVar at "/spec/template/spec/containers/*/env", schema has type "object"`),
+				field.Invalid(field.NewPath("containers").Index(0).Child("volumeMounts"), "/volumeMounts", `"/spec/template/spec/containers/*/volumeMounts" is not defined by the schema`),
+				field.Invalid(field.NewPath("volumes"), "/spec/template/spec/volumes", `expected int32 at "/spec/template/spec/volumes/*/secret/defaultMode", schema has type "string"`),
+			},
+		},
+		{
+			name:    "incompatible map properties",
+			mapping: mapping,
+			crd: `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  versions:
+  - name: v1
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              template:
+                type: object
+                properties:
+                  metadata:
+                    type: object
+                    properties:
+                      annotations:
+                        type: object
+                        properties:
+                          zone:
+                            type: integer
+                          app:
+                            type: boolean
+                          tier:
+                            type: number
+                      labels:
+                        type: object
+                        additionalProperties:
+                          type: string
+                  spec:
+                    type: object
+                    x-kubernetes-preserve-unknown-fields: true
+`,
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("annotations"), "/spec/template/metadata/annotations", `expected string at "/spec/template/metadata/annotations/app", schema has type "boolean"`),
+			},
+		},
+		{
+			name: "path does not find objects",
+			mapping: PodMapping{
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.containers[*].name",
+					},
+					{
+						Path: ".spec.image.containers[*]",
+					},
+					{
+						Path: "..containers[*]",
+					},
+				},
+			},
+			crd: `
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+spec:
+  version: v1
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            image:
+              type: string
+            containers:
+              type: array
+              items:
+                type: object
+                properties:
+                  name:
+                    type: string
+            template:
+              type: object
+              x-kubernetes-preserve-unknown-fields: true
+`,
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("containers").Index(0).Child("path"), ".spec.containers[*].name", `expected object at "/spec/containers/*/name", schema has type "string"`),
+				field.Invalid(field.NewPath("containers").Index(1).Child("path"), ".spec.image.containers[*]", `expected object at "/spec/image", schema has type "string"`),
+			},
+		},
+		{
+			name: "invalid mapping",
+			mapping: PodMapping{
+				Volumes: "volumes",
+			},
+			crd: `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  versions:
+  - name: v1
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`,
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("volumes"), "volumes", "must be a JSON Pointer starting with '/'"),
+			},
+		},
+		{
+			name:    "unknown version",
+			mapping: mapping,
+			version: "v2",
+			crd: `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  versions:
+  - name: v1
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`,
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			m := &c.mapping
+			m.Default()
+			actual, err := m.ValidateCRD([]byte(c.crd), c.version)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("ValidateCRD() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("ValidateCRD() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package binding
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// SecretGetter gets a secret by name, from the namespace of the workloads being bound. The
+// SecretNamespaceLister of a client-go informer satisfies the interface, while a clientset is
+// adapted with SecretGetterFromClient.
+type SecretGetter interface {
+	Get(name string) (*corev1.Secret, error)
+}
+
+// SecretGetterFunc adapts a function to a SecretGetter.
+type SecretGetterFunc func(name string) (*corev1.Secret, error)
+
+func (f SecretGetterFunc) Get(name string) (*corev1.Secret, error) {
+	return f(name)
+}
+
+// SecretGetterFromClient adapts the secrets of a namespace from a clientset, such as
+// `clientset.CoreV1().Secrets(namespace)`, to a SecretGetter. Each secret is read from the API
+// server.
+func SecretGetterFromClient(client corev1client.SecretInterface) SecretGetter {
+	return SecretGetterFunc(func(name string) (*corev1.Secret, error) {
+		return client.Get(context.Background(), name, metav1.GetOptions{})
+	})
+}
+
+// SecretErrorReason describes why a binding's secret cannot be projected.
+type SecretErrorReason string
+
+const (
+	// SecretNotFound is a secret that does not exist.
+	SecretNotFound SecretErrorReason = "NotFound"
+	// SecretMissingKeys is a secret without the `type` entry required by the spec, or without
+	// an entry projected as an environment variable.
+	SecretMissingKeys SecretErrorReason = "MissingKeys"
+)
+
+// SecretError is returned when the secret referenced by a binding cannot be projected. Pods
+// referencing the secret would otherwise fail to start with CreateContainerConfigError.
+type SecretError struct {
+	// Binding is the name of the binding that could not be applied.
+	Binding string
+	// Secret is the name of the secret.
+	Secret string
+	// Reason describes why the secret cannot be projected.
+	Reason SecretErrorReason
+	// Missing is the keys absent from the secret, in sorted order.
+	// +optional
+	Missing []string
+	// Err is the error returned by the SecretGetter for a secret that was not found.
+	// +optional
+	Err error
+}
+
+func (e *SecretError) Error() string {
+	if e.Reason == SecretNotFound {
+		return fmt.Sprintf("binding %q: secret %q not found", e.Binding, e.Secret)
+	}
+	missing := make([]string, len(e.Missing))
+	for i, key := range e.Missing {
+		missing[i] = fmt.Sprintf("%q", key)
+	}
+	return fmt.Sprintf("binding %q: secret %q is missing keys %s", e.Binding, e.Secret, strings.Join(missing, ", "))
+}
+
+func (e *SecretError) Unwrap() error {
+	return e.Err
+}
+
+// checkSecret verifies that the binding's secret exists and holds the entries the binding
+// projects. The `type` entry is always required, unless overridden by the binding's Type, as
+// are the keys of the binding's Env. Nothing is checked without a getter or a secret.
+func (b *Binding) checkSecret(getter SecretGetter) error {
+	if getter == nil || b.Secret.Name == "" {
+		return nil
+	}
+	secret, err := getter.Get(b.Secret.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &SecretError{
+				Binding: b.Name,
+				Secret:  b.Secret.Name,
+				Reason:  SecretNotFound,
+				Err:     err,
+			}
+		}
+		return fmt.Errorf("getting secret %q: %w", b.Secret.Name, err)
+	}
+
+	required := map[string]bool{}
+	if b.Type == "" {
+		required["type"] = true
+	}
+	for _, m := range b.Env {
+		if (m.Key == "type" && b.Type != "") || (m.Key == "provider" && b.Provider != "") {
+			continue
+		}
+		required[m.Key] = true
+	}
+	missing := []string{}
+	for key := range required {
+		if _, ok := secret.Data[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return &SecretError{
+		Binding: b.Name,
+		Secret:  b.Secret.Name,
+		Reason:  SecretMissingKeys,
+		Missing: missing,
+	}
+}
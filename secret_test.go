@@ -0,0 +1,243 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestBind_SecretGetter(t *testing.T) {
+	secrets := SecretGetterFunc(func(name string) (*corev1.Secret, error) {
+		switch name {
+		case "db-secret":
+			return &corev1.Secret{
+				Data: map[string][]byte{
+					"type":     []byte("mysql"),
+					"username": []byte("admin"),
+				},
+			}, nil
+		case "untyped-secret":
+			return &corev1.Secret{
+				Data: map[string][]byte{
+					"username": []byte("admin"),
+				},
+			}, nil
+		case "broken-secret":
+			return nil, fmt.Errorf("connection refused")
+		}
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	})
+
+	tests := []struct {
+		name        string
+		binding     Binding
+		getter      SecretGetter
+		expectedErr *SecretError
+		otherErr    bool
+	}{
+		{
+			name: "valid secret",
+			binding: Binding{
+				Name:   "db",
+				Secret: corev1.LocalObjectReference{Name: "db-secret"},
+				Env:    []EnvMapping{{Name: "DB_USER", Key: "username"}},
+			},
+			getter: secrets,
+		},
+		{
+			name: "unchecked without getter",
+			binding: Binding{
+				Name:   "db",
+				Secret: corev1.LocalObjectReference{Name: "missing-secret"},
+			},
+		},
+		{
+			name: "secret not found",
+			binding: Binding{
+				Name:   "db",
+				Secret: corev1.LocalObjectReference{Name: "missing-secret"},
+			},
+			getter: secrets,
+			expectedErr: &SecretError{
+				Binding: "db",
+				Secret:  "missing-secret",
+				Reason:  SecretNotFound,
+			},
+		},
+		{
+			name: "missing type",
+			binding: Binding{
+				Name:   "db",
+				Secret: corev1.LocalObjectReference{Name: "untyped-secret"},
+			},
+			getter: secrets,
+			expectedErr: &SecretError{
+				Binding: "db",
+				Secret:  "untyped-secret",
+				Reason:  SecretMissingKeys,
+				Missing: []string{"type"},
+			},
+		},
+		{
+			name: "type overridden by binding",
+			binding: Binding{
+				Name:   "db",
+				Secret: corev1.LocalObjectReference{Name: "untyped-secret"},
+				Type:   "mysql",
+				Env:    []EnvMapping{{Name: "DB_TYPE", Key: "type"}},
+			},
+			getter: secrets,
+		},
+		{
+			name: "missing env keys",
+			binding: Binding{
+				Name:     "db",
+				Secret:   corev1.LocalObjectReference{Name: "db-secret"},
+				Provider: "bitnami",
+				Env: []EnvMapping{
+					{Name: "DB_USER", Key: "username"},
+					{Name: "DB_PASSWORD", Key: "password"},
+					{Name: "DB_HOST", Key: "host"},
+					{Name: "DB_PROVIDER", Key: "provider"},
+				},
+			},
+			getter: secrets,
+			expectedErr: &SecretError{
+				Binding: "db",
+				Secret:  "db-secret",
+				Reason:  SecretMissingKeys,
+				Missing: []string{"host", "password"},
+			},
+		},
+		{
+			name: "getter error",
+			binding: Binding{
+				Name:   "db",
+				Secret: corev1.LocalObjectReference{Name: "broken-secret"},
+			},
+			getter:   secrets,
+			otherErr: true,
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			seed := &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "web"}},
+						},
+					},
+				},
+			}
+			actual := seed.DeepCopy()
+			m := &PodMapping{}
+			m.Default()
+			_, err := c.binding.Bind(actual, m, BindOptions{SecretGetter: c.getter})
+
+			if c.otherErr {
+				var secretErr *SecretError
+				if err == nil || errors.As(err, &secretErr) {
+					t.Errorf("Bind() expected a getter error, got: %v", err)
+				}
+			} else if c.expectedErr == nil {
+				if err != nil {
+					t.Fatalf("Bind() unexpected error: %v", err)
+				}
+				return
+			} else {
+				var secretErr *SecretError
+				if !errors.As(err, &secretErr) {
+					t.Fatalf("Bind() expected SecretError, got: %v", err)
+				}
+				if diff := cmp.Diff(c.expectedErr, secretErr, cmpopts.IgnoreFields(SecretError{}, "Err")); diff != "" {
+					t.Errorf("Bind() (-expected, +actual): %s", diff)
+				}
+			}
+			if diff := cmp.Diff(seed, actual); diff != "" {
+				t.Errorf("Bind() modified workload (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestSecretGetterFromClient(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db-secret"},
+			Data: map[string][]byte{
+				"type": []byte("mysql"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "untyped-secret"},
+		},
+	)
+	getter := SecretGetterFromClient(clientset.CoreV1().Secrets("default"))
+	m := &PodMapping{}
+	m.Default()
+	workload := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "hello"}},
+					},
+				},
+			},
+		}
+	}
+
+	b := Binding{Name: "db", Secret: corev1.LocalObjectReference{Name: "db-secret"}}
+	if _, err := b.Bind(workload(), m, BindOptions{SecretGetter: getter}); err != nil {
+		t.Errorf("Bind() unexpected error: %v", err)
+	}
+
+	// secrets of other namespaces are not found
+	b = Binding{Name: "db", Secret: corev1.LocalObjectReference{Name: "untyped-secret"}}
+	_, err := b.Bind(workload(), m, BindOptions{SecretGetter: getter})
+	var secretErr *SecretError
+	if !errors.As(err, &secretErr) || secretErr.Reason != SecretNotFound {
+		t.Fatalf("Bind() expected SecretError with reason %s, got: %v", SecretNotFound, err)
+	}
+	if !apierrors.IsNotFound(secretErr.Err) {
+		t.Errorf("Bind() expected the clientset's NotFound error, got: %v", secretErr.Err)
+	}
+
+	gets := []string{}
+	for _, action := range clientset.Actions() {
+		if get, ok := action.(clienttesting.GetAction); ok {
+			gets = append(gets, fmt.Sprintf("%s %s/%s", get.GetResource().Resource, get.GetNamespace(), get.GetName()))
+		}
+	}
+	expected := []string{"secrets default/db-secret", "secrets default/untyped-secret"}
+	if diff := cmp.Diff(expected, gets); diff != "" {
+		t.Errorf("SecretGetterFromClient() gets (-expected, +actual): %s", diff)
+	}
+}
+
+func TestSecretError(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "db-secret")
+	err := error(&SecretError{Binding: "db", Secret: "db-secret", Reason: SecretNotFound, Err: notFound})
+	if expected := `binding "db": secret "db-secret" not found`; err.Error() != expected {
+		t.Errorf("Error() = %q, expected %q", err.Error(), expected)
+	}
+	if !apierrors.IsNotFound(errors.Unwrap(err)) {
+		t.Errorf("Unwrap() expected NotFound, got: %v", errors.Unwrap(err))
+	}
+
+	err = &SecretError{Binding: "db", Secret: "db-secret", Reason: SecretMissingKeys, Missing: []string{"host", "type"}}
+	if expected := `binding "db": secret "db-secret" is missing keys "host", "type"`; err.Error() != expected {
+		t.Errorf("Error() = %q, expected %q", err.Error(), expected)
+	}
+}
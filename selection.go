@@ -0,0 +1,129 @@
+package binding
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RegexpPrefix marks a container name pattern as a regular expression rather than a glob.
+const RegexpPrefix = "regexp:"
+
+// DefaultExcludedContainers is the names of well known sidecar and injected containers that are
+// not bound unless a Binding names them in its Containers or overrides its ContainerSelection.
+var DefaultExcludedContainers = []string{
+	"istio-init",
+	"istio-proxy",
+	"istio-validation",
+	"linkerd-init",
+	"linkerd-network-validator",
+	"linkerd-proxy",
+}
+
+// DefaultContainerSelection returns the selection used by a Binding that does not define its
+// own. Every container is selected except for the DefaultExcludedContainers.
+func DefaultContainerSelection() *ContainerSelection {
+	return &ContainerSelection{
+		Init: ContainerSelector{
+			Exclude: append([]string{}, DefaultExcludedContainers...),
+		},
+		App: ContainerSelector{
+			Exclude: append([]string{}, DefaultExcludedContainers...),
+		},
+	}
+}
+
+type ContainerSelection struct {
+	// Roles restricts binding to containers labeled with one of the roles by their
+	// ContainerMapping. If empty, containers of every role other than RoleEphemeral are bound.
+	// Ephemeral containers are only bound when RoleEphemeral is listed explicitly, as their
+	// environment and volume mounts are restricted.
+	// +optional
+	Roles []string
+	// Init selects the init containers to bind, those with the RoleInit role.
+	Init ContainerSelector
+	// App selects the application containers to bind, those with any role other than
+	// RoleInit.
+	App ContainerSelector
+}
+
+type ContainerSelector struct {
+	// Include is a set of patterns for the names of containers to bind. Patterns are globs as
+	// understood by path.Match, or regular expressions when prefixed with `regexp:`. Regular
+	// expressions are not implicitly anchored. If empty, all containers are included.
+	// +optional
+	Include []string
+	// Exclude is a set of patterns for the names of containers to skip. Exclusions take
+	// precedence over inclusions.
+	// +optional
+	Exclude []string
+}
+
+// Matches returns true if the container name is included and not excluded by the selector.
+func (s *ContainerSelector) Matches(name string) (bool, error) {
+	excluded, err := matchesAny(s.Exclude, name)
+	if err != nil || excluded {
+		return false, err
+	}
+	if len(s.Include) == 0 {
+		return true, nil
+	}
+	return matchesAny(s.Include, name)
+}
+
+// selects returns true if the binding applies to the container.
+func (b *Binding) selects(c *MetaContainer) (bool, error) {
+	named := len(b.Containers) != 0
+	if named && !contains(b.Containers, c.Name) {
+		return false, nil
+	}
+	selection := b.Selection
+	switch {
+	case selection == nil && named:
+		// containers named by the binding are bound even if excluded by default
+		selection = &ContainerSelection{}
+	case selection == nil:
+		selection = DefaultContainerSelection()
+	}
+	if len(selection.Roles) != 0 && !contains(selection.Roles, c.Role) {
+		return false, nil
+	}
+	if c.Role == RoleEphemeral && !contains(selection.Roles, RoleEphemeral) {
+		return false, nil
+	}
+	selector := &selection.App
+	if c.Role == RoleInit {
+		selector = &selection.Init
+	}
+	selected, err := selector.Matches(c.Name)
+	if err != nil {
+		return false, fmt.Errorf("binding %q: %w", b.Name, err)
+	}
+	return selected, nil
+}
+
+func matchesAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := matches(pattern, name)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+func matches(pattern, name string) (bool, error) {
+	if strings.HasPrefix(pattern, RegexpPrefix) {
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, RegexpPrefix))
+		if err != nil {
+			return false, fmt.Errorf("invalid container pattern %q: %w", pattern, err)
+		}
+		return re.MatchString(name), nil
+	}
+	matched, err := path.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid container pattern %q: %w", pattern, err)
+	}
+	return matched, nil
+}
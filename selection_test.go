@@ -0,0 +1,183 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContainerSelector_Matches(t *testing.T) {
+	tests := []struct {
+		name        string
+		selector    ContainerSelector
+		container   string
+		expected    bool
+		expectedErr bool
+	}{
+		{
+			name:      "empty selector",
+			selector:  ContainerSelector{},
+			container: "hello",
+			expected:  true,
+		},
+		{
+			name: "included by name",
+			selector: ContainerSelector{
+				Include: []string{"hello"},
+			},
+			container: "hello",
+			expected:  true,
+		},
+		{
+			name: "not included",
+			selector: ContainerSelector{
+				Include: []string{"hello"},
+			},
+			container: "hello-2",
+			expected:  false,
+		},
+		{
+			name: "included by glob",
+			selector: ContainerSelector{
+				Include: []string{"hello-*"},
+			},
+			container: "hello-2",
+			expected:  true,
+		},
+		{
+			name: "included by regexp",
+			selector: ContainerSelector{
+				Include: []string{"regexp:^hello-[0-9]+$"},
+			},
+			container: "hello-2",
+			expected:  true,
+		},
+		{
+			name: "regexp is not anchored",
+			selector: ContainerSelector{
+				Include: []string{"regexp:proxy"},
+			},
+			container: "istio-proxy",
+			expected:  true,
+		},
+		{
+			name: "excluded",
+			selector: ContainerSelector{
+				Exclude: []string{"*-proxy"},
+			},
+			container: "istio-proxy",
+			expected:  false,
+		},
+		{
+			name: "exclude takes precedence",
+			selector: ContainerSelector{
+				Include: []string{"*"},
+				Exclude: []string{"istio-proxy"},
+			},
+			container: "istio-proxy",
+			expected:  false,
+		},
+		{
+			name: "invalid glob",
+			selector: ContainerSelector{
+				Include: []string{"["},
+			},
+			container:   "hello",
+			expectedErr: true,
+		},
+		{
+			name: "invalid regexp",
+			selector: ContainerSelector{
+				Exclude: []string{"regexp:("},
+			},
+			container:   "hello",
+			expectedErr: true,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := c.selector.Matches(c.container)
+
+			if (err != nil) != c.expectedErr {
+				t.Errorf("Matches() expected err: %v", err)
+			}
+			if c.expectedErr {
+				return
+			}
+			if c.expected != actual {
+				t.Errorf("Matches() expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBinding_selects(t *testing.T) {
+	containers := []MetaContainer{
+		{Name: "istio-init", Role: RoleInit},
+		{Name: "hello", Role: RoleApp},
+		{Name: "istio-proxy", Role: RoleApp},
+		{Name: "debugger", Role: RoleEphemeral},
+	}
+	tests := []struct {
+		name     string
+		binding  Binding
+		expected []string
+	}{
+		{
+			name:     "default selection",
+			binding:  Binding{},
+			expected: []string{"hello"},
+		},
+		{
+			name: "named containers",
+			binding: Binding{
+				Containers: []string{"hello"},
+			},
+			expected: []string{"hello"},
+		},
+		{
+			name: "named containers bypass the default exclusions",
+			binding: Binding{
+				Containers: []string{"istio-init", "istio-proxy"},
+			},
+			expected: []string{"istio-init", "istio-proxy"},
+		},
+		{
+			name: "named ephemeral containers are not bound by default",
+			binding: Binding{
+				Containers: []string{"debugger"},
+			},
+			expected: []string{},
+		},
+		{
+			name: "named containers excluded by an explicit selection",
+			binding: Binding{
+				Containers: []string{"hello", "istio-proxy"},
+				Selection: &ContainerSelection{
+					App: ContainerSelector{
+						Exclude: []string{"istio-*"},
+					},
+				},
+			},
+			expected: []string{"hello"},
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			actual := []string{}
+			for i := range containers {
+				selected, err := c.binding.selects(&containers[i])
+				if err != nil {
+					t.Fatalf("selects() unexpected err: %v", err)
+				}
+				if selected {
+					actual = append(actual, containers[i].Name)
+				}
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("selects() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
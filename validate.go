@@ -0,0 +1,50 @@
+package binding
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Validate checks the mapping is well formed. The mapping should be defaulted.
+func (m *PodMapping) Validate() field.ErrorList {
+	errs := field.ErrorList{}
+
+	errs = append(errs, validatePointer(m.Annotations, field.NewPath("annotations"), true)...)
+	errs = append(errs, validatePointer(m.Labels, field.NewPath("labels"), false)...)
+	for i := range m.Containers {
+		errs = append(errs, m.Containers[i].Validate(field.NewPath("containers").Index(i))...)
+	}
+	errs = append(errs, validatePointer(m.Volumes, field.NewPath("volumes"), true)...)
+
+	return errs
+}
+
+// Validate checks the container mapping is well formed. The mapping should be defaulted.
+func (m *ContainerMapping) Validate(fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if m.Path == "" {
+		errs = append(errs, field.Required(fldPath.Child("path"), ""))
+	} else if _, err := jsonpath.Parse("", fmt.Sprintf("{%s}", m.Path)); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("path"), m.Path, err.Error()))
+	}
+	errs = append(errs, validatePointer(m.Name, fldPath.Child("name"), false)...)
+	errs = append(errs, validatePointer(m.Env, fldPath.Child("env"), true)...)
+	errs = append(errs, validatePointer(m.VolumeMounts, fldPath.Child("volumeMounts"), true)...)
+
+	return errs
+}
+
+func validatePointer(ptr string, fldPath *field.Path, required bool) field.ErrorList {
+	errs := field.ErrorList{}
+	switch {
+	case ptr == "" && required:
+		errs = append(errs, field.Required(fldPath, ""))
+	case ptr != "" && !strings.HasPrefix(ptr, "/"):
+		errs = append(errs, field.Invalid(fldPath, ptr, "must be a JSON Pointer starting with '/'"))
+	}
+	return errs
+}
@@ -0,0 +1,56 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestPodMapping_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  PodMapping
+		expected field.ErrorList
+	}{
+		{
+			name:     "default",
+			mapping:  PodMapping{},
+			expected: field.ErrorList{},
+		},
+		{
+			name: "invalid",
+			mapping: PodMapping{
+				Annotations: "spec/annotations",
+				Containers: []ContainerMapping{
+					{
+						Path: ".spec.containers[",
+						Name: "name",
+					},
+					{
+						Name: "/name",
+						Env:  "env",
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("annotations"), "spec/annotations", "must be a JSON Pointer starting with '/'"),
+				field.Invalid(field.NewPath("containers").Index(0).Child("path"), ".spec.containers[", "unterminated array"),
+				field.Invalid(field.NewPath("containers").Index(0).Child("name"), "name", "must be a JSON Pointer starting with '/'"),
+				field.Required(field.NewPath("containers").Index(1).Child("path"), ""),
+				field.Invalid(field.NewPath("containers").Index(1).Child("env"), "env", "must be a JSON Pointer starting with '/'"),
+			},
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			m := &c.mapping
+			m.Default()
+			actual := m.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("Validate() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON omits the value of remove operations, while other operations keep a value even
+// when it is null.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{o.Op, o.Path})
+	}
+	type operation Operation
+	return json.Marshal(operation(o))
+}
+
+// CreatePatch returns the JSON Patch transforming the original unstructured value into the
+// modified value. Objects are compared field by field in sorted order. Arrays are compared by
+// index when only their tail is added or removed, such as an environment variable appended to a
+// container, otherwise the array is replaced as a whole.
+func CreatePatch(original, modified interface{}) []Operation {
+	return diff("", original, modified, []Operation{})
+}
+
+func diff(path string, original, modified interface{}, ops []Operation) []Operation {
+	switch o := original.(type) {
+	case map[string]interface{}:
+		m, ok := modified.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(o)+len(m))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		for k := range m {
+			if _, ok := o[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + escape(k)
+			ov, inOriginal := o[k]
+			mv, inModified := m[k]
+			switch {
+			case !inModified:
+				ops = append(ops, Operation{Op: "remove", Path: p})
+			case !inOriginal:
+				ops = append(ops, Operation{Op: "add", Path: p, Value: mv})
+			default:
+				ops = diff(p, ov, mv, ops)
+			}
+		}
+		return ops
+	case []interface{}:
+		m, ok := modified.([]interface{})
+		if !ok {
+			break
+		}
+		shared := len(o)
+		if len(m) < shared {
+			shared = len(m)
+		}
+		if !equality.Semantic.DeepEqual(o[:shared], m[:shared]) && len(o) != len(m) {
+			// items were inserted or removed before the tail, indexes no longer line up
+			break
+		}
+		for i := 0; i < shared; i++ {
+			ops = diff(fmt.Sprintf("%s/%d", path, i), o[i], m[i], ops)
+		}
+		for i := shared; i < len(m); i++ {
+			ops = append(ops, Operation{Op: "add", Path: fmt.Sprintf("%s/%d", path, i), Value: m[i]})
+		}
+		// remove from the end so the remaining indexes are stable
+		for i := len(o) - 1; i >= shared; i-- {
+			ops = append(ops, Operation{Op: "remove", Path: fmt.Sprintf("%s/%d", path, i)})
+		}
+		return ops
+	}
+	if equality.Semantic.DeepEqual(original, modified) {
+		return ops
+	}
+	return append(ops, Operation{Op: "replace", Path: path, Value: modified})
+}
+
+// escape encodes the key as a JSON Pointer reference token.
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreatePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		modified string
+		expected string
+	}{
+		{
+			name:     "unchanged",
+			original: `{"a":{"b":[1,2]}}`,
+			modified: `{"a":{"b":[1,2]}}`,
+			expected: `[]`,
+		},
+		{
+			name:     "fields",
+			original: `{"a":1,"b":{"c":"d"},"e":true}`,
+			modified: `{"a":2,"b":{"c":"d","f":null},"g":"h"}`,
+			expected: `[{"op":"replace","path":"/a","value":2},{"op":"add","path":"/b/f","value":null},{"op":"remove","path":"/e"},{"op":"add","path":"/g","value":"h"}]`,
+		},
+		{
+			name:     "escaped keys",
+			original: `{"annotations":{}}`,
+			modified: `{"annotations":{"example.com/a~b":"c"}}`,
+			expected: `[{"op":"add","path":"/annotations/example.com~1a~0b","value":"c"}]`,
+		},
+		{
+			name:     "appended items",
+			original: `{"env":[{"name":"A"}]}`,
+			modified: `{"env":[{"name":"A"},{"name":"B"},{"name":"C"}]}`,
+			expected: `[{"op":"add","path":"/env/1","value":{"name":"B"}},{"op":"add","path":"/env/2","value":{"name":"C"}}]`,
+		},
+		{
+			name:     "changed items",
+			original: `{"env":[{"name":"A"},{"name":"B"}]}`,
+			modified: `{"env":[{"name":"A","value":"a"},{"name":"B"}]}`,
+			expected: `[{"op":"add","path":"/env/0/value","value":"a"}]`,
+		},
+		{
+			name:     "removed items",
+			original: `{"env":[{"name":"A"},{"name":"B"},{"name":"C"}]}`,
+			modified: `{"env":[{"name":"A"}]}`,
+			expected: `[{"op":"remove","path":"/env/2"},{"op":"remove","path":"/env/1"}]`,
+		},
+		{
+			name:     "inserted items",
+			original: `{"env":[{"name":"B"}]}`,
+			modified: `{"env":[{"name":"A"},{"name":"B"}]}`,
+			expected: `[{"op":"replace","path":"/env","value":[{"name":"A"},{"name":"B"}]}]`,
+		},
+		{
+			name:     "changed type",
+			original: `{"a":{"b":"c"}}`,
+			modified: `{"a":["b"]}`,
+			expected: `[{"op":"replace","path":"/a","value":["b"]}]`,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			var original, modified interface{}
+			if err := json.Unmarshal([]byte(c.original), &original); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(c.modified), &modified); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := json.Marshal(CreatePatch(original, modified))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expected, string(actual)); diff != "" {
+				t.Errorf("CreatePatch() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package webhook serves a mutating admission webhook that binds workloads as they are created
+// or updated.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+
+	binding "github.com/scothis/unstructured-meta-binding"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// maxRequestBytes bounds the size of an AdmissionReview. The API server limits requests to 3MiB,
+// an AdmissionReview for an update holds both the old and new object.
+const maxRequestBytes = 7 * 1024 * 1024
+
+// BindingLookup finds the bindings to apply to a workload under admission.
+type BindingLookup interface {
+	// Lookup returns the bindings for the object. An object with no bindings is admitted
+	// unchanged. Errors are treated as a failure of the webhook, rather than a reason to deny
+	// the object.
+	Lookup(ctx context.Context, req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) ([]binding.Binding, error)
+}
+
+// BindingLookupFunc adapts a function to a BindingLookup.
+type BindingLookupFunc func(ctx context.Context, req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) ([]binding.Binding, error)
+
+func (f BindingLookupFunc) Lookup(ctx context.Context, req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) ([]binding.Binding, error) {
+	return f(ctx, req, obj)
+}
+
+// StaticBindings applies the same bindings to every workload.
+type StaticBindings []binding.Binding
+
+func (s StaticBindings) Lookup(ctx context.Context, req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) ([]binding.Binding, error) {
+	return s, nil
+}
+
+// Handler is an http.Handler for `admission.k8s.io/v1` AdmissionReview requests. Workloads of a
+// kind known to the Registry are bound with the bindings found by the lookup, and the changes
+// are returned as a JSON Patch.
+//
+// Errors are reported so the webhook's failurePolicy applies: malformed requests and lookup
+// failures respond with an HTTP error, and the API server admits or rejects the object as the
+// policy dictates. A binding that cannot be applied to the object, for example because of a
+// conflicting volume, is a problem with the object and is denied regardless of the policy.
+//
+// Binding has no side effects, so dry run requests are patched the same as any other request and
+// the webhook may be registered with `sideEffects: None`.
+type Handler struct {
+	// Registry holds the mappings of the kinds to bind. Objects of other kinds are admitted
+	// unchanged, with a warning.
+	Registry binding.Registry
+	// Bindings finds the bindings to apply to each object.
+	Bindings BindingLookup
+	// Options used to bind each object.
+	// +optional
+	Options binding.BindOptions
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s is not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, fmt.Sprintf("unsupported content type %q, expected application/json", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("malformed AdmissionReview: %s", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview has no request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.Admit(r.Context(), review.Request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.UID = review.Request.UID
+	out, err := json.Marshal(&admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionv1.SchemeGroupVersion.String(),
+			Kind:       "AdmissionReview",
+		},
+		Response: resp,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out)
+}
+
+// Admit binds the object of the request, returning a response that patches the object. An error
+// is returned when the request cannot be processed, in which case the webhook's failurePolicy
+// should apply.
+func (h *Handler) Admit(ctx context.Context, req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	resp := &admissionv1.AdmissionResponse{
+		UID:     req.UID,
+		Allowed: true,
+	}
+	if (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) || req.SubResource != "" {
+		return resp, nil
+	}
+
+	gvk := schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind}
+	m, ok := h.Registry.Lookup(gvk)
+	if !ok {
+		resp.Warnings = []string{fmt.Sprintf("meta-binding: no mapping for kind %s, not bound", gvk.GroupKind())}
+		return resp, nil
+	}
+
+	original := map[string]interface{}{}
+	if err := utiljson.Unmarshal(req.Object.Raw, &original); err != nil {
+		return nil, fmt.Errorf("malformed object: %w", err)
+	}
+	obj := &unstructured.Unstructured{Object: original}
+	bindings, err := h.Bindings.Lookup(ctx, req, obj)
+	if err != nil {
+		return nil, fmt.Errorf("looking up bindings for %s %q: %w", gvk.Kind, name(req, obj), err)
+	}
+	if len(bindings) == 0 {
+		return resp, nil
+	}
+
+	bound := obj.DeepCopy()
+	result, err := binding.BindAll(bound, m, bindings, h.Options)
+	if err != nil {
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("binding %s %q: %s", gvk.Kind, name(req, obj), err),
+		}
+		return resp, nil
+	}
+	if !result.Changed {
+		return resp, nil
+	}
+
+	patch, err := json.Marshal(CreatePatch(obj.Object, bound.Object))
+	if err != nil {
+		return nil, err
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	resp.Patch = patch
+	resp.PatchType = &patchType
+	return resp, nil
+}
+
+// name returns the name of the object under admission. Objects created with generateName do not
+// have a name yet.
+func name(req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) string {
+	if req.Name != "" {
+		return req.Name
+	}
+	if obj.GetName() != "" {
+		return obj.GetName()
+	}
+	return obj.GetGenerateName()
+}
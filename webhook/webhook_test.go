@@ -0,0 +1,319 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	binding "github.com/scothis/unstructured-meta-binding"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestHandler(t *testing.T) {
+	db := binding.Binding{
+		Name: "db",
+		Secret: corev1.LocalObjectReference{
+			Name: "db-secret",
+		},
+	}
+	boundPatch := []interface{}{
+		map[string]interface{}{
+			"op":   "add",
+			"path": "/spec/template/metadata/annotations",
+			"value": map[string]interface{}{
+				"meta-binding.scothis.github.io/bindings": `{"db":{"volume":"binding-db","env":{"web":["SERVICE_BINDING_ROOT"]},"volumeMounts":{"web":["/bindings/db"]}}}`,
+			},
+		},
+		map[string]interface{}{
+			"op":   "add",
+			"path": "/spec/template/spec/containers/0/env/1",
+			"value": map[string]interface{}{
+				"name":  "SERVICE_BINDING_ROOT",
+				"value": "/bindings",
+			},
+		},
+		map[string]interface{}{
+			"op":   "add",
+			"path": "/spec/template/spec/containers/0/volumeMounts",
+			"value": []interface{}{
+				map[string]interface{}{
+					"name":      "binding-db",
+					"mountPath": "/bindings/db",
+					"readOnly":  true,
+				},
+			},
+		},
+		map[string]interface{}{
+			"op":   "add",
+			"path": "/spec/template/spec/volumes",
+			"value": []interface{}{
+				map[string]interface{}{
+					"name": "binding-db",
+					"secret": map[string]interface{}{
+						"secretName": "db-secret",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		fixture          string
+		bindings         BindingLookup
+		expectedUID      types.UID
+		expectedAllowed  bool
+		expectedPatch    []interface{}
+		expectedWarnings []string
+		expectedCode     int32
+		expectedMessage  string
+	}{
+		{
+			name:            "binds deployment",
+			fixture:         "deployment-create.json",
+			bindings:        StaticBindings{db},
+			expectedUID:     "705ab4f5-6393-11e8-b7cc-42010a800002",
+			expectedAllowed: true,
+			expectedPatch:   boundPatch,
+		},
+		{
+			name:            "dry run",
+			fixture:         "deployment-dry-run.json",
+			bindings:        StaticBindings{db},
+			expectedUID:     "705ab4f5-6393-11e8-b7cc-42010a800003",
+			expectedAllowed: true,
+			expectedPatch:   boundPatch,
+		},
+		{
+			name:            "already bound",
+			fixture:         "deployment-update-bound.json",
+			bindings:        StaticBindings{db},
+			expectedUID:     "705ab4f5-6393-11e8-b7cc-42010a800007",
+			expectedAllowed: true,
+		},
+		{
+			name:            "no bindings",
+			fixture:         "deployment-create.json",
+			bindings:        StaticBindings{},
+			expectedUID:     "705ab4f5-6393-11e8-b7cc-42010a800002",
+			expectedAllowed: true,
+		},
+		{
+			name:    "bindings from lookup",
+			fixture: "deployment-create.json",
+			bindings: BindingLookupFunc(func(ctx context.Context, req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) ([]binding.Binding, error) {
+				if req.Namespace != "default" || obj.GetName() != "web" {
+					return nil, nil
+				}
+				return []binding.Binding{db}, nil
+			}),
+			expectedUID:     "705ab4f5-6393-11e8-b7cc-42010a800002",
+			expectedAllowed: true,
+			expectedPatch:   boundPatch,
+		},
+		{
+			name:             "unknown kind",
+			fixture:          "widget-create.json",
+			bindings:         StaticBindings{db},
+			expectedUID:      "705ab4f5-6393-11e8-b7cc-42010a800004",
+			expectedAllowed:  true,
+			expectedWarnings: []string{"meta-binding: no mapping for kind Widget.example.com, not bound"},
+		},
+		{
+			name:            "delete",
+			fixture:         "deployment-delete.json",
+			bindings:        StaticBindings{db},
+			expectedUID:     "705ab4f5-6393-11e8-b7cc-42010a800006",
+			expectedAllowed: true,
+		},
+		{
+			name:            "conflict",
+			fixture:         "deployment-conflict.json",
+			bindings:        StaticBindings{db},
+			expectedUID:     "705ab4f5-6393-11e8-b7cc-42010a800005",
+			expectedAllowed: false,
+			expectedCode:    http.StatusUnprocessableEntity,
+			expectedMessage: `binding Deployment "web": binding "db": `,
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(&Handler{
+				Registry: binding.DefaultRegistry(),
+				Bindings: c.bindings,
+			})
+			defer server.Close()
+
+			body, err := ioutil.ReadFile(filepath.Join("testdata", c.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("ServeHTTP() unexpected status %d", resp.StatusCode)
+			}
+
+			review := &admissionv1.AdmissionReview{}
+			if err := json.NewDecoder(resp.Body).Decode(review); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := "admission.k8s.io/v1", review.APIVersion; expected != actual {
+				t.Errorf("ServeHTTP() expected apiVersion %q, got %q", expected, actual)
+			}
+			if expected, actual := "AdmissionReview", review.Kind; expected != actual {
+				t.Errorf("ServeHTTP() expected kind %q, got %q", expected, actual)
+			}
+			r := review.Response
+			if r == nil {
+				t.Fatalf("ServeHTTP() expected response")
+			}
+			if expected, actual := c.expectedUID, r.UID; expected != actual {
+				t.Errorf("ServeHTTP() expected uid %q, got %q", expected, actual)
+			}
+			if expected, actual := c.expectedAllowed, r.Allowed; expected != actual {
+				t.Errorf("ServeHTTP() expected allowed %v, got %v", expected, actual)
+			}
+			if diff := cmp.Diff(c.expectedWarnings, r.Warnings); diff != "" {
+				t.Errorf("ServeHTTP() warnings (-expected, +actual): %s", diff)
+			}
+
+			if c.expectedPatch == nil {
+				if r.Patch != nil || r.PatchType != nil {
+					t.Errorf("ServeHTTP() expected no patch, got %s", r.Patch)
+				}
+			} else {
+				if r.PatchType == nil || *r.PatchType != admissionv1.PatchTypeJSONPatch {
+					t.Errorf("ServeHTTP() expected patch type %q, got %v", admissionv1.PatchTypeJSONPatch, r.PatchType)
+				}
+				patch := []interface{}{}
+				if err := json.Unmarshal(r.Patch, &patch); err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(c.expectedPatch, patch); diff != "" {
+					t.Errorf("ServeHTTP() patch (-expected, +actual): %s", diff)
+				}
+			}
+
+			if c.expectedCode == 0 {
+				if r.Result != nil {
+					t.Errorf("ServeHTTP() expected no result, got %v", r.Result)
+				}
+				return
+			}
+			if r.Result == nil {
+				t.Fatalf("ServeHTTP() expected result")
+			}
+			if expected, actual := c.expectedCode, r.Result.Code; expected != actual {
+				t.Errorf("ServeHTTP() expected code %d, got %d", expected, actual)
+			}
+			if !strings.HasPrefix(r.Result.Message, c.expectedMessage) {
+				t.Errorf("ServeHTTP() expected message starting with %q, got %q", c.expectedMessage, r.Result.Message)
+			}
+		})
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", "deployment-create.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		contentType        string
+		body               string
+		bindings           BindingLookup
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "wrong method",
+			method:             http.MethodGet,
+			contentType:        "application/json",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedBody:       "method GET is not allowed\n",
+		},
+		{
+			name:               "wrong content type",
+			method:             http.MethodPost,
+			contentType:        "application/yaml",
+			body:               string(fixture),
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedBody:       "unsupported content type \"application/yaml\", expected application/json\n",
+		},
+		{
+			name:               "malformed review",
+			method:             http.MethodPost,
+			contentType:        "application/json; charset=utf-8",
+			body:               "{",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "malformed AdmissionReview: unexpected end of JSON input\n",
+		},
+		{
+			name:               "missing request",
+			method:             http.MethodPost,
+			contentType:        "application/json",
+			body:               `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "AdmissionReview has no request\n",
+		},
+		{
+			name:        "lookup failure",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        string(fixture),
+			bindings: BindingLookupFunc(func(ctx context.Context, req *admissionv1.AdmissionRequest, obj *unstructured.Unstructured) ([]binding.Binding, error) {
+				return nil, fmt.Errorf("lister not synced")
+			}),
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "looking up bindings for Deployment \"web\": lister not synced\n",
+		},
+	}
+
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(&Handler{
+				Registry: binding.DefaultRegistry(),
+				Bindings: c.bindings,
+			})
+			defer server.Close()
+
+			req, err := http.NewRequest(c.method, server.URL, strings.NewReader(c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", c.contentType)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+
+			if expected, actual := c.expectedStatusCode, resp.StatusCode; expected != actual {
+				t.Errorf("ServeHTTP() expected status %d, got %d", expected, actual)
+			}
+			if diff := cmp.Diff(c.expectedBody, string(body)); diff != "" {
+				t.Errorf("ServeHTTP() body (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package binding
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// WorkloadLister lists the workloads of a single kind. The GenericLister of a client-go informer
+// satisfies the interface. Listed objects are never modified, as those of a lister belong to the
+// informer's shared cache.
+type WorkloadLister interface {
+	List(selector labels.Selector) ([]runtime.Object, error)
+}
+
+// WorkloadListerFunc adapts a function to a WorkloadLister.
+type WorkloadListerFunc func(selector labels.Selector) ([]runtime.Object, error)
+
+func (f WorkloadListerFunc) List(selector labels.Selector) ([]runtime.Object, error) {
+	return f(selector)
+}
+
+// WorkloadSelector selects the workloads of a kind by their labels.
+type WorkloadSelector struct {
+	// Kind of the workloads. An empty version matches every version of the kind.
+	Kind schema.GroupVersionKind
+	// Selector is matched against the labels of the workload. A nil selector matches nothing.
+	Selector labels.Selector
+}
+
+// Matches returns true if the object is of the selected kind and its labels match.
+func (s WorkloadSelector) Matches(obj runtime.Object) (bool, error) {
+	if !s.matchesKind(obj.GetObjectKind().GroupVersionKind()) {
+		return false, nil
+	}
+	return s.matchesLabels(obj)
+}
+
+func (s WorkloadSelector) matchesKind(gvk schema.GroupVersionKind) bool {
+	if s.Kind.Version != "" && s.Kind.Version != gvk.Version {
+		return false
+	}
+	return s.Kind.GroupKind() == gvk.GroupKind()
+}
+
+func (s WorkloadSelector) matchesLabels(obj runtime.Object) (bool, error) {
+	if s.Selector == nil {
+		return false, nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false, err
+	}
+	return s.Selector.Matches(labels.Set(accessor.GetLabels())), nil
+}
+
+// WorkloadResult is the outcome of binding a single selected workload.
+type WorkloadResult struct {
+	// Object is a copy of the workload with the binding applied or removed. The object passed in
+	// is not modified.
+	Object runtime.Object
+	// Matched is true for a workload matching the selector, which the binding was applied to.
+	// A workload that no longer matches has the binding removed instead.
+	Matched bool
+	// Result describes the changes made to the workload.
+	Result BindResult
+	// Err is the reason the workload could not be bound or unbound.
+	// +optional
+	Err error
+}
+
+// BindSelected applies the binding to each object matching the selector, such as the
+// documents of a manifest stream. Objects of the selected kind that no longer match, but were
+// previously bound by the binding, have the binding removed so that a workload whose labels
+// changed is not left bound. Other objects are ignored and have no result.
+//
+// A result is returned for each workload bound or unbound, in the order of the objects, holding
+// a modified copy of the workload to be written back. A workload that fails does not stop the
+// others from being bound, its error is recorded in the result and included in the returned
+// aggregate.
+func (b *Binding) BindSelected(objs []runtime.Object, s WorkloadSelector, m *PodMapping, opts BindOptions) ([]WorkloadResult, error) {
+	selected := []runtime.Object{}
+	for _, obj := range objs {
+		if s.matchesKind(obj.GetObjectKind().GroupVersionKind()) {
+			selected = append(selected, obj)
+		}
+	}
+	return b.bindSelected(selected, s, m, opts)
+}
+
+// BindListed applies the binding to each workload listed by the lister that matches the
+// selector, removing it from listed workloads that no longer match. Every listed workload is
+// assumed to be of the selected kind, as objects from a lister commonly have no kind set.
+// Results are returned as for BindSelected.
+func (b *Binding) BindListed(lister WorkloadLister, s WorkloadSelector, m *PodMapping, opts BindOptions) ([]WorkloadResult, error) {
+	// workloads that no longer match must also be found, so the selector cannot be pushed down
+	objs, err := lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	return b.bindSelected(objs, s, m, opts)
+}
+
+func (b *Binding) bindSelected(objs []runtime.Object, s WorkloadSelector, m *PodMapping, opts BindOptions) ([]WorkloadResult, error) {
+	results := []WorkloadResult{}
+	errs := []error{}
+	for _, obj := range objs {
+		// the objects may be shared, such as those from an informer's cache
+		obj = obj.DeepCopyObject()
+		matched, err := s.matchesLabels(obj)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", describeObject(obj), err))
+			results = append(results, WorkloadResult{Object: obj, Err: err})
+			continue
+		}
+		result := WorkloadResult{Object: obj, Matched: matched}
+		applied := true
+		if matched {
+			result.Result, result.Err = b.Bind(obj, m, opts)
+		} else {
+			applied, result.Result, result.Err = b.unbindResult(obj, m)
+		}
+		if !applied {
+			continue
+		}
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", describeObject(obj), result.Err))
+		}
+		results = append(results, result)
+	}
+	return results, utilerrors.NewAggregate(errs)
+}
+
+// unbindResult removes the binding from the object, describing the changes. Applied is false
+// if the object was not bound by the binding, in which case it is not modified.
+func (b *Binding) unbindResult(obj runtime.Object, m *PodMapping) (applied bool, result BindResult, err error) {
+	mpt, err := m.ToMeta(obj)
+	if err != nil {
+		return true, BindResult{}, err
+	}
+	records, err := readRecords(mpt.Annotations)
+	if err != nil {
+		return true, BindResult{}, err
+	}
+	if _, ok := records[b.Name]; !ok {
+		return false, BindResult{}, nil
+	}
+	before := mpt.DeepCopy()
+	if err := unbind(&mpt, b.Name); err != nil {
+		return true, BindResult{}, err
+	}
+	if err := m.FromMeta(obj, mpt); err != nil {
+		return true, BindResult{}, err
+	}
+	return true, newBindResult(before, &mpt, nil), nil
+}
+
+// describeObject names the object for an error message.
+func describeObject(obj runtime.Object) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		kind = "workload"
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return kind
+	}
+	if accessor.GetNamespace() == "" {
+		return fmt.Sprintf("%s %q", kind, accessor.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, accessor.GetNamespace(), accessor.GetName())
+}
@@ -0,0 +1,275 @@
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBindSelected(t *testing.T) {
+	deployment := func(name string, partOf string, volumes ...corev1.Volume) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app.kubernetes.io/part-of": partOf},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "web"}},
+						Volumes:    volumes,
+					},
+				},
+			},
+		}
+	}
+	statefulSet := &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "cache",
+			Labels: map[string]string{"app.kubernetes.io/part-of": "shop"},
+		},
+	}
+	m := &PodMapping{}
+	m.Default()
+	b := Binding{
+		Name:   "db",
+		Secret: corev1.LocalObjectReference{Name: "db-secret"},
+	}
+	// a workload bound before its labels changed
+	moved := deployment("moved", "blog")
+	if _, err := b.Bind(moved, m, BindOptions{}); err != nil {
+		t.Fatalf("Bind() unexpected error: %v", err)
+	}
+
+	type summary struct {
+		Name    string
+		Matched bool
+		Changed bool
+		Volumes Changes
+		Err     bool
+	}
+	tests := []struct {
+		name        string
+		objs        []runtime.Object
+		selector    WorkloadSelector
+		expected    []summary
+		expectedErr bool
+	}{
+		{
+			name: "binds matching workloads",
+			objs: []runtime.Object{
+				deployment("web", "shop"),
+				deployment("blog", "blog"),
+				statefulSet,
+				deployment("api", "shop"),
+			},
+			selector: WorkloadSelector{
+				Kind:     schema.GroupVersionKind{Group: "apps", Kind: "Deployment"},
+				Selector: labels.SelectorFromSet(labels.Set{"app.kubernetes.io/part-of": "shop"}),
+			},
+			expected: []summary{
+				{Name: "web", Matched: true, Changed: true, Volumes: Changes{Added: []string{"binding-db"}}},
+				{Name: "api", Matched: true, Changed: true, Volumes: Changes{Added: []string{"binding-db"}}},
+			},
+		},
+		{
+			name: "unbinds workloads no longer matching",
+			objs: []runtime.Object{
+				deployment("web", "shop"),
+				moved.DeepCopy(),
+			},
+			selector: WorkloadSelector{
+				Kind:     schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				Selector: labels.SelectorFromSet(labels.Set{"app.kubernetes.io/part-of": "shop"}),
+			},
+			expected: []summary{
+				{Name: "web", Matched: true, Changed: true, Volumes: Changes{Added: []string{"binding-db"}}},
+				{Name: "moved", Matched: false, Changed: true, Volumes: Changes{Removed: []string{"binding-db"}}},
+			},
+		},
+		{
+			name: "other versions are ignored",
+			objs: []runtime.Object{
+				deployment("web", "shop"),
+			},
+			selector: WorkloadSelector{
+				Kind:     schema.GroupVersionKind{Group: "apps", Version: "v1beta1", Kind: "Deployment"},
+				Selector: labels.Everything(),
+			},
+			expected: []summary{},
+		},
+		{
+			name: "nil selector matches nothing",
+			objs: []runtime.Object{
+				deployment("web", "shop"),
+			},
+			selector: WorkloadSelector{
+				Kind: schema.GroupVersionKind{Group: "apps", Kind: "Deployment"},
+			},
+			expected: []summary{},
+		},
+		{
+			name: "failed workload does not stop others",
+			objs: []runtime.Object{
+				deployment("web", "shop", corev1.Volume{Name: "binding-db"}),
+				deployment("api", "shop"),
+			},
+			selector: WorkloadSelector{
+				Kind:     schema.GroupVersionKind{Group: "apps", Kind: "Deployment"},
+				Selector: labels.Everything(),
+			},
+			expected: []summary{
+				{Name: "web", Matched: true, Err: true},
+				{Name: "api", Matched: true, Changed: true, Volumes: Changes{Added: []string{"binding-db"}}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "workload without metadata does not stop others",
+			objs: []runtime.Object{
+				&withoutMeta{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}},
+				deployment("api", "shop"),
+			},
+			selector: WorkloadSelector{
+				Kind:     schema.GroupVersionKind{Group: "apps", Kind: "Deployment"},
+				Selector: labels.Everything(),
+			},
+			expected: []summary{
+				{Err: true},
+				{Name: "api", Matched: true, Changed: true, Volumes: Changes{Added: []string{"binding-db"}}},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			results, err := b.BindSelected(c.objs, c.selector, m, BindOptions{})
+			if (err != nil) != c.expectedErr {
+				t.Errorf("BindSelected() expected err %v, got: %v", c.expectedErr, err)
+			}
+			actual := []summary{}
+			for _, r := range results {
+				name := ""
+				if d, ok := r.Object.(*appsv1.Deployment); ok {
+					name = d.Name
+				}
+				actual = append(actual, summary{
+					Name:    name,
+					Matched: r.Matched,
+					Changed: r.Result.Changed,
+					Volumes: r.Result.Volumes,
+					Err:     r.Err != nil,
+				})
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("BindSelected() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+// withoutMeta is a workload without object metadata, its labels cannot be matched.
+type withoutMeta struct {
+	metav1.TypeMeta
+}
+
+func (w *withoutMeta) DeepCopyObject() runtime.Object {
+	return &withoutMeta{TypeMeta: w.TypeMeta}
+}
+
+func TestBindListed(t *testing.T) {
+	// objects from a lister have no kind set
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "web",
+			Labels: map[string]string{"app.kubernetes.io/part-of": "shop"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "web"}},
+				},
+			},
+		},
+	}
+	blog := web.DeepCopy()
+	blog.Name = "blog"
+	blog.Labels["app.kubernetes.io/part-of"] = "blog"
+
+	m := &PodMapping{}
+	m.Default()
+	var listed labels.Selector
+	lister := WorkloadListerFunc(func(selector labels.Selector) ([]runtime.Object, error) {
+		listed = selector
+		return []runtime.Object{web, blog}, nil
+	})
+	b := Binding{
+		Name:   "db",
+		Secret: corev1.LocalObjectReference{Name: "db-secret"},
+	}
+	selector := WorkloadSelector{
+		Kind:     schema.GroupVersionKind{Group: "apps", Kind: "Deployment"},
+		Selector: labels.SelectorFromSet(labels.Set{"app.kubernetes.io/part-of": "shop"}),
+	}
+
+	cached := []*appsv1.Deployment{web.DeepCopy(), blog.DeepCopy()}
+
+	results, err := b.BindListed(lister, selector, m, BindOptions{})
+	if err != nil {
+		t.Fatalf("BindListed() unexpected error: %v", err)
+	}
+	if !listed.Empty() {
+		t.Errorf("BindListed() listed with selector %q, expected everything", listed)
+	}
+	if len(results) != 1 || !results[0].Matched || !results[0].Result.Changed {
+		t.Fatalf("BindListed() unexpected results: %+v", results)
+	}
+	bound := results[0].Object.(*appsv1.Deployment)
+	if bound == web {
+		t.Errorf("BindListed() expected a copy of the listed workload")
+	}
+	if diff := cmp.Diff([]string{"binding-db"}, volumeNames(bound)); diff != "" {
+		t.Errorf("BindListed() volumes (-expected, +actual): %s", diff)
+	}
+	// objects from a lister belong to the informer's cache and must not be modified
+	if diff := cmp.Diff(cached, []*appsv1.Deployment{web, blog}); diff != "" {
+		t.Errorf("BindListed() modified listed workloads (-expected, +actual): %s", diff)
+	}
+
+	// the binding is removed once the bound workload stops matching
+	web = bound
+	web.Labels["app.kubernetes.io/part-of"] = "blog"
+	cached = []*appsv1.Deployment{web.DeepCopy(), blog.DeepCopy()}
+	results, err = b.BindListed(lister, selector, m, BindOptions{})
+	if err != nil {
+		t.Fatalf("BindListed() unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Matched {
+		t.Fatalf("BindListed() unexpected results: %+v", results)
+	}
+	if diff := cmp.Diff(Changes{Removed: []string{"binding-db"}}, results[0].Result.Volumes); diff != "" {
+		t.Errorf("BindListed() (-expected, +actual): %s", diff)
+	}
+	if diff := cmp.Diff([]string{}, volumeNames(results[0].Object.(*appsv1.Deployment))); diff != "" {
+		t.Errorf("BindListed() volumes (-expected, +actual): %s", diff)
+	}
+	if diff := cmp.Diff(cached, []*appsv1.Deployment{web, blog}); diff != "" {
+		t.Errorf("BindListed() modified listed workloads (-expected, +actual): %s", diff)
+	}
+}
+
+func volumeNames(d *appsv1.Deployment) []string {
+	names := []string{}
+	for _, v := range d.Spec.Template.Spec.Volumes {
+		names = append(names, v.Name)
+	}
+	return names
+}